@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"song-library/configs"
+	"song-library/db"
+	"song-library/logger"
+	"song-library/models"
+)
+
+type songKey struct {
+	library uint
+	group   string
+	song    string
+}
+
+// Dedup reports active songs of one library whose group and title collide once normalised,
+// which the unique index on (library_id, group_key, song_key) does not allow. With -backfill it also fills the
+// keys of existing rows; of every colliding cluster only one song gets its key, the others
+// are listed and must be merged or deleted before running it again.
+func main() {
+	backfill := flag.Bool("backfill", false, "fill the normalised keys of existing songs")
+	flag.Parse()
+
+	if err := run(*backfill); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(backfill bool) error {
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("Error loading .env file: %v\n", err)
+	}
+
+	if err := configs.ReadSettings(); err != nil {
+		return fmt.Errorf("error reading settings: %w", err)
+	}
+
+	if configs.AppSettings.DatabaseParams.Driver == models.DriverMemory {
+		return fmt.Errorf("dedup needs a persistent database, driver is %q", models.DriverMemory)
+	}
+
+	if err := logger.Init(); err != nil {
+		return fmt.Errorf("error initializing logger: %w", err)
+	}
+
+	if err := db.ConnectToDB(); err != nil {
+		return fmt.Errorf("error connecting to DB: %w", err)
+	}
+	defer func() {
+		if err := db.CloseDBConn(); err != nil {
+			fmt.Printf("Error closing database connection: %v\n", err)
+		}
+	}()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("error initializing database migrations: %w", err)
+	}
+
+	var songs []models.Song
+	if err := db.GetDBConn().Unscoped().Order("id").Find(&songs).Error; err != nil {
+		return fmt.Errorf("error reading songs: %w", err)
+	}
+
+	// The keeper of a cluster is the song that already holds the key, or else the oldest one.
+	var order []songKey
+	clusters := make(map[songKey][]models.Song)
+	keepers := make(map[songKey]uint)
+	for _, song := range songs {
+		if song.DeletedAt.Valid {
+			continue
+		}
+		key := songKey{library: song.LibraryID, group: models.NormalizeKey(song.Group), song: models.NormalizeKey(song.Song)}
+		if _, ok := clusters[key]; !ok {
+			order = append(order, key)
+			keepers[key] = song.ID
+		}
+		clusters[key] = append(clusters[key], song)
+		if song.GroupKey == key.group && song.SongKey == key.song && key.group != "" {
+			keepers[key] = song.ID
+		}
+	}
+
+	duplicates := 0
+	for _, key := range order {
+		cluster := clusters[key]
+		if len(cluster) < 2 {
+			continue
+		}
+		duplicates += len(cluster) - 1
+		fmt.Printf("library %d, %q / %q: %d songs, keeping %d\n", key.library, key.group, key.song, len(cluster), keepers[key])
+		for _, song := range cluster {
+			fmt.Printf("  %d  %q / %q\n", song.ID, song.Group, song.Song)
+		}
+	}
+	fmt.Printf("Found %d duplicate songs\n", duplicates)
+
+	if !backfill {
+		return nil
+	}
+
+	filled := 0
+	for _, song := range songs {
+		expected := song
+		expected.SetKeys()
+		if expected.GroupKey == song.GroupKey && expected.SongKey == song.SongKey {
+			continue
+		}
+		if !song.DeletedAt.Valid && keepers[songKey{library: song.LibraryID, group: expected.GroupKey, song: expected.SongKey}] != song.ID {
+			continue
+		}
+
+		err := db.GetDBConn().Unscoped().Model(&models.Song{}).Where("id = ?", song.ID).UpdateColumns(map[string]interface{}{
+			"group_key": expected.GroupKey,
+			"song_key":  expected.SongKey,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("error filling keys of song %d: %w", song.ID, err)
+		}
+		filled++
+	}
+	fmt.Printf("Filled the keys of %d songs, skipped %d duplicates\n", filled, duplicates)
+	return nil
+}
@@ -8,10 +8,16 @@ import (
 	"os/signal"
 	"song-library/configs"
 	"song-library/db"
+	"song-library/i18n"
 	"song-library/logger"
+	"song-library/models"
 	"song-library/pkg/handlers"
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/memory"
+	services "song-library/pkg/services"
 	"song-library/server"
 	"syscall"
+	"time"
 )
 
 // @title Song Library API 🎶
@@ -25,6 +31,11 @@ import (
 // @contact.url    https://t.me/parvizjon_hasanov
 // @contact.email  hy.parvizjon@outlook.com
 
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key; it can also be sent as "Authorization: Bearer <key>", which is also how user access tokens are sent.
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		fmt.Printf("Error loading .env file: %v\n", err)
@@ -44,24 +55,86 @@ func main() {
 	}
 	fmt.Println("Logger initialized successfully")
 
-	if err := db.ConnectToDB(); err != nil {
-		fmt.Printf("Error connecting to DB: %v\n", err)
+	var repos repository.Repositories
+	var uow repository.UnitOfWork
+	switch configs.AppSettings.DatabaseParams.Driver {
+	case models.DriverMemory:
+		store := memory.NewStore()
+		repos = memory.NewRepositories(store)
+		uow = memory.NewUnitOfWork(store)
+		fmt.Println("Using in-memory storage, data is lost on shutdown")
+	default:
+		if err := db.ConnectToDB(); err != nil {
+			fmt.Printf("Error connecting to DB: %v\n", err)
+			return
+		}
+		defer func() {
+			if err := db.CloseDBConn(); err != nil {
+				fmt.Printf("Error closing database connection: %v\n", err)
+			} else {
+				fmt.Println("Database connection closed successfully")
+			}
+		}()
+		fmt.Println("Connected to the database successfully")
+
+		if err := db.Migrate(); err != nil {
+			fmt.Printf("Error initializing database migrations: %v\n", err)
+			return
+		}
+		fmt.Println("Database migrations completed successfully")
+
+		isolation, err := db.IsolationLevel()
+		if err != nil {
+			fmt.Printf("Error reading database settings: %v\n", err)
+			return
+		}
+		repos = repository.NewRoutedRepositories(db.GetRouter())
+		uow = repository.NewUnitOfWork(db.GetDBConn(), isolation)
+	}
+
+	songService := services.NewSongService(repos, uow)
+	songDetailService := services.NewSongDetailService(repos.SongDetails)
+	libraryService := services.NewLibraryService(repos.Libraries)
+	apiKeyService := services.NewAPIKeyService(repos.APIKeys, repos.Libraries)
+	if adminKey := os.Getenv("ADMIN_API_KEY"); adminKey != "" {
+		if err := apiKeyService.EnsureAPIKey(context.Background(), "bootstrap", adminKey, []string{models.ScopeAdmin}); err != nil {
+			fmt.Printf("Error storing the ADMIN_API_KEY key: %v\n", err)
+			return
+		}
+		fmt.Println("ADMIN_API_KEY key is available")
+	}
+	var userService *services.UserService
+	if configs.AppSettings.AuthParams.Enabled {
+		tokens, err := services.NewTokenIssuer(configs.AppSettings.AuthParams)
+		if err != nil {
+			fmt.Printf("Error reading auth settings: %v\n", err)
+			return
+		}
+		userService = services.NewUserService(repos.Users, repos.Libraries, tokens)
+	}
+	locales, err := i18n.Load(configs.AppSettings.LocaleParams)
+	if err != nil {
+		fmt.Printf("Error loading message catalogues: %v\n", err)
 		return
 	}
-	defer func() {
-		if err := db.CloseDBConn(); err != nil {
-			fmt.Printf("Error closing database connection: %v\n", err)
-		} else {
-			fmt.Println("Database connection closed successfully")
+	handler := handlers.NewHandler(songService, songDetailService, libraryService, apiKeyService, userService, services.NewRateLimiter(repos.Quotas), services.NewAuditService(repos.Audit), services.NewIdempotencyService(repos.Idempotency), locales)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if replicas := configs.AppSettings.DatabaseParams.Replicas; len(replicas) > 0 && configs.AppSettings.DatabaseParams.Driver != models.DriverMemory {
+		interval := time.Duration(configs.AppSettings.DatabaseParams.ReplicaCheckIntervalSeconds) * time.Second
+		if interval <= 0 {
+			interval = 10 * time.Second
 		}
-	}()
-	fmt.Println("Connected to the database successfully")
+		go db.GetRouter().MonitorReplicas(ctx, interval)
+		fmt.Println("Replica monitor started")
+	}
 
-	if err := db.Migrate(); err != nil {
-		fmt.Printf("Error initializing database migrations: %v\n", err)
-		return
+	if configs.AppSettings.LinkCheckParams.Enabled {
+		go services.NewLinkChecker(repos.Links, songService).Start(ctx)
+		fmt.Println("Link health checker started")
 	}
-	fmt.Println("Database migrations completed successfully")
 
 	mainServer := new(server.Server)
 	secondServer := new(server.Server)
@@ -69,7 +142,7 @@ func main() {
 	go func() {
 		appPort := configs.AppSettings.AppParams.PortRun
 		fmt.Printf("Starting application server on port %s\n", appPort)
-		if err := mainServer.Run(appPort, handlers.InitRoutes()); err != nil {
+		if err := mainServer.Run(appPort, handler.InitRoutes()); err != nil {
 			fmt.Printf("Error starting application server: %s\n", err)
 		}
 	}()
@@ -77,7 +150,7 @@ func main() {
 	go func() {
 		apiPort := configs.AppSettings.AppParams.ApiPortRun
 		fmt.Printf("Starting API server on port %s\n", apiPort)
-		if err := mainServer.Run(apiPort, handlers.InitRoutes()); err != nil {
+		if err := mainServer.Run(apiPort, handler.InitRoutes()); err != nil {
 			fmt.Printf("Error starting API server: %s\n", err)
 		}
 	}()
@@ -86,6 +159,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 	fmt.Println("Shutting down servers...")
+	cancel()
 
 	if err := mainServer.Shutdown(context.Background()); err != nil {
 		fmt.Printf("Error during application server shutdown: %s\n", err)
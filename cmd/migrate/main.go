@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"song-library/configs"
+	"song-library/db"
+	"song-library/logger"
+	"song-library/models"
+	"strconv"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up         apply all pending migrations
+  down [N]   revert the last N applied migrations (default 1)
+  to N       migrate up or down to version N, 0 reverts everything
+  status     list migrations and when they were applied
+`
+
+// Migrate manages the database schema through the versioned migrations embedded in the
+// db package.
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("no command given")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("Error loading .env file: %v\n", err)
+	}
+
+	if err := configs.ReadSettings(); err != nil {
+		return fmt.Errorf("error reading settings: %w", err)
+	}
+
+	if configs.AppSettings.DatabaseParams.Driver == models.DriverMemory {
+		return fmt.Errorf("migrations need a persistent database, driver is %q", models.DriverMemory)
+	}
+
+	if err := logger.Init(); err != nil {
+		return fmt.Errorf("error initializing logger: %w", err)
+	}
+
+	if err := db.ConnectToDB(); err != nil {
+		return fmt.Errorf("error connecting to DB: %w", err)
+	}
+	defer func() {
+		if err := db.CloseDBConn(); err != nil {
+			fmt.Printf("Error closing database connection: %v\n", err)
+		}
+	}()
+
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		return db.Migrate()
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+			steps = n
+		}
+		return db.MigrateDown(steps)
+	case command == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		return db.MigrateTo(version)
+	case command == "status" && len(args) == 1:
+		return printStatus()
+	}
+
+	flag.Usage()
+	return fmt.Errorf("invalid command %q", args[0])
+}
+
+func printStatus() error {
+	statuses, err := db.GetMigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-40s %s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"os"
+	"os/signal"
+	"song-library/models"
+	"song-library/pkg/mockprovider"
+	"song-library/server"
+	"syscall"
+)
+
+// Mockprovider is a stand-alone metadata API serving song details from a fixture file, so
+// enrichment can be developed and tested without the real provider. Point app_params.api_url
+// at http://localhost:<port>/info?group=%s&song=%s to use it.
+func main() {
+	port := flag.String("port", "8282", "port to listen on")
+	fixturesFile := flag.String("fixtures", "configs/song_details.json", "path to the song details fixture file")
+	latency := flag.Int("latency-ms", 0, "fixed delay added to every lookup in milliseconds")
+	jitter := flag.Int("jitter-ms", 0, "random extra delay up to this many milliseconds")
+	errorRate := flag.Float64("error-rate", 0, "share of lookups answered with -error-status (0..1)")
+	errorStatus := flag.Int("error-status", 500, "status code of injected errors")
+	malformedRate := flag.Float64("malformed-rate", 0, "share of lookups answered with malformed JSON (0..1)")
+	requestLogSize := flag.Int("request-log-size", mockprovider.DefaultRequestLogSize, "most recent requests kept for /_mock/requests")
+	flag.Parse()
+
+	settings := mockprovider.Settings{
+		LatencyMs:      *latency,
+		JitterMs:       *jitter,
+		ErrorRate:      *errorRate,
+		ErrorStatus:    *errorStatus,
+		MalformedRate:  *malformedRate,
+		RequestLogSize: *requestLogSize,
+	}
+	if err := settings.Validate(); err != nil {
+		fmt.Printf("Invalid settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	fixtures, err := readFixtures(*fixturesFile)
+	if err != nil {
+		fmt.Printf("Error reading fixtures: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %d fixtures from %s\n", len(fixtures), *fixturesFile)
+
+	gin.SetMode(gin.ReleaseMode)
+	provider := mockprovider.New(fixtures, settings)
+	mockServer := new(server.Server)
+
+	go func() {
+		fmt.Printf("Starting mock provider on port %s\n", *port)
+		if err := mockServer.Run(*port, provider.Routes()); err != nil {
+			fmt.Printf("Error starting mock provider: %s\n", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+	<-quit
+
+	if err := mockServer.Shutdown(context.Background()); err != nil {
+		fmt.Printf("Error during mock provider shutdown: %s\n", err)
+	}
+	fmt.Println("Mock provider shut down gracefully")
+}
+
+func readFixtures(file string) ([]models.SongDetail, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []models.SongDetail
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"song-library/configs"
+	"song-library/db"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	services "song-library/pkg/services"
+)
+
+// Seed fills the song_details table behind /API/info from a JSON file holding an array
+// of models.SongDetail, creating missing entries and replacing existing ones.
+func main() {
+	file := flag.String("file", "configs/song_details.json", "path to the song details JSON file")
+	flag.Parse()
+
+	if err := run(*file); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(file string) error {
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("Error loading .env file: %v\n", err)
+	}
+
+	if err := configs.ReadSettings(); err != nil {
+		return fmt.Errorf("error reading settings: %w", err)
+	}
+
+	if configs.AppSettings.DatabaseParams.Driver == models.DriverMemory {
+		return fmt.Errorf("seeding needs a persistent database, driver is %q", models.DriverMemory)
+	}
+
+	if err := logger.Init(); err != nil {
+		return fmt.Errorf("error initializing logger: %w", err)
+	}
+
+	if err := db.ConnectToDB(); err != nil {
+		return fmt.Errorf("error connecting to DB: %w", err)
+	}
+	defer func() {
+		if err := db.CloseDBConn(); err != nil {
+			fmt.Printf("Error closing database connection: %v\n", err)
+		}
+	}()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("error initializing database migrations: %w", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading seed file: %w", err)
+	}
+
+	var songDetails []models.SongDetail
+	if err := json.Unmarshal(data, &songDetails); err != nil {
+		return fmt.Errorf("error decoding seed file: %w", err)
+	}
+
+	songDetailService := services.NewSongDetailService(repository.NewSongDetailRepository(db.GetDBConn()))
+	created, updated, err := songDetailService.BulkUpsertSongDetails(context.Background(), songDetails)
+	if err != nil {
+		return fmt.Errorf("error seeding song details: %w", err)
+	}
+	fmt.Printf("Seeded song details from %s: %d created, %d updated\n", file, created, updated)
+	return nil
+}
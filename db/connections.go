@@ -1,41 +1,149 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"os"
 	"song-library/configs"
 	"song-library/logger"
+	"song-library/models"
+	"time"
 )
 
-var dbConn *gorm.DB
+var (
+	dbConn *gorm.DB
+	router *Router
+)
 
 func ConnectToDB() error {
-	if os.Getenv("DB_PASSWORD") == "" {
-		logger.Error.Printf("")
-		return fmt.Errorf("DB_PASSWORD environment variable is not set")
-	}
+	var dialector gorm.Dialector
+	switch params := configs.AppSettings.DatabaseParams; params.Driver {
+	case models.DriverPostgres, "":
+		if os.Getenv("DB_PASSWORD") == "" {
+			logger.Error.Printf("[db.ConnectToDB]: DB_PASSWORD environment variable is not set")
+			return fmt.Errorf("DB_PASSWORD environment variable is not set")
+		}
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s",
-		configs.AppSettings.PostgresParams.Host,
-		configs.AppSettings.PostgresParams.Port,
-		configs.AppSettings.PostgresParams.User,
-		configs.AppSettings.PostgresParams.Database,
-		os.Getenv("DB_PASSWORD"),
-	)
+		dialector = postgres.Open(postgresDSN(params.Host, params.Port))
+	case models.DriverSQLite:
+		if params.SQLitePath == "" {
+			return fmt.Errorf("sqlite_path is not set")
+		}
+		dialector = sqlite.Open(sqliteDSN(params.SQLitePath))
+	default:
+		return fmt.Errorf("unsupported database driver %q", params.Driver)
+	}
 
-	db, err := gorm.Open(postgres.Open(connStr), &gorm.Config{})
+	// TranslateError turns driver specific constraint violations into gorm.ErrDuplicatedKey
+	// and friends, so repositories can map them without knowing the driver.
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
 	if err != nil {
 		fmt.Printf("Failed to connect to database: %v\n", err)
 		return err
 	}
+	if configs.AppSettings.DatabaseParams.Driver == models.DriverSQLite && configs.AppSettings.DatabaseParams.SQLitePath == ":memory:" {
+		// Every connection to ":memory:" opens its own empty database, so keep a single one
+		// open for good; the pool settings do not apply.
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	} else if err := configurePool(db); err != nil {
+		return err
+	}
 	fmt.Println("Connected to database")
 	dbConn = db
+
+	router = newRouter(db, time.Duration(configs.AppSettings.DatabaseParams.MaxReplicaLagSeconds)*time.Second)
+	if err := connectReplicas(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func postgresDSN(host, port string) string {
+	params := configs.AppSettings.DatabaseParams
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s",
+		host,
+		port,
+		params.User,
+		params.Database,
+		os.Getenv("DB_PASSWORD"),
+	)
+}
+
+// sqliteDSN turns on foreign keys, which are off by default in SQLite, WAL, which lets
+// readers work next to a writer, and a busy timeout, which makes concurrent writers wait for
+// the lock instead of failing. Transactions take the write lock when they begin, so one that
+// reads before writing cannot fail halfway because another writer got in between; units of
+// work and migrations (see lockMigrations) rely on that.
+func sqliteDSN(path string) string {
+	return path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+// configurePool applies the pool limits from database_params; unset values keep the
+// database/sql defaults.
+func configurePool(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	params := configs.AppSettings.DatabaseParams
+	if params.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(params.MaxOpenConns)
+	}
+	if params.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(params.MaxIdleConns)
+	}
+	if params.ConnMaxLifetimeSeconds > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(params.ConnMaxLifetimeSeconds) * time.Second)
+	}
+	if params.ConnMaxIdleTimeSeconds > 0 {
+		sqlDB.SetConnMaxIdleTime(time.Duration(params.ConnMaxIdleTimeSeconds) * time.Second)
+	}
+	return nil
+}
+
+// connectReplicas opens the read replicas of database_params. They are not pinged here:
+// an unreachable replica only keeps reads on the primary until a check finds it usable.
+func connectReplicas() error {
+	params := configs.AppSettings.DatabaseParams
+	if len(params.Replicas) == 0 {
+		return nil
+	}
+	if params.Driver != models.DriverPostgres && params.Driver != "" {
+		return fmt.Errorf("read replicas are only supported by the postgres driver")
+	}
+
+	for _, replica := range params.Replicas {
+		db, err := gorm.Open(postgres.Open(postgresDSN(replica.Host, replica.Port)), &gorm.Config{
+			TranslateError:       true,
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			fmt.Printf("Failed to open replica %s:%s: %v\n", replica.Host, replica.Port, err)
+			return err
+		}
+		if err := configurePool(db); err != nil {
+			return err
+		}
+		router.addReplica(replica.Host+":"+replica.Port, db)
+	}
+	router.checkReplicas(context.Background())
+	fmt.Printf("Using %d read replica(s)\n", len(params.Replicas))
 	return nil
 }
 
 func CloseDBConn() error {
+	if err := router.close(); err != nil {
+		return err
+	}
+
 	sqlDB, err := dbConn.DB()
 	if err != nil {
 		return err
@@ -50,3 +158,8 @@ func CloseDBConn() error {
 func GetDBConn() *gorm.DB {
 	return dbConn
 }
+
+// GetRouter returns the router between the primary and the read replicas.
+func GetRouter() *Router {
+	return router
+}
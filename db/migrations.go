@@ -1,28 +1,331 @@
 package db
 
 import (
+	"embed"
 	"errors"
 	"fmt"
-	"song-library/models"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func Migrate() (err error) {
+// migrationLockID is the key of the Postgres advisory lock held while a migration runs, so
+// instances starting at the same time apply every migration exactly once.
+const migrationLockID = 4728315096
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is one numbered schema change, read from migrations/<dialect>/NNNN_name.up.sql
+// and the matching .down.sql file. Statements use IF [NOT] EXISTS wherever the dialect
+// allows it, so a migration can run against a schema that already has its tables, such as
+// one created by the earlier AutoMigrate setup. SQLite has no such form for adding and
+// dropping columns.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies all pending migrations.
+func Migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return MigrateTo(migrations[len(migrations)-1].Version)
+}
+
+// MigrateDown reverts the given number of most recently applied migrations.
+func MigrateDown(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("number of migrations to revert must be positive, got %d", steps)
+	}
+
+	statuses, err := GetMigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	var applied []int
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			applied = append(applied, status.Version)
+		}
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+	if steps >= len(applied) {
+		return MigrateTo(0)
+	}
+	return MigrateTo(applied[len(applied)-steps-1])
+}
+
+// MigrateTo applies or reverts migrations one at a time until the schema is at the given
+// version. Every step runs in its own transaction under the migration lock and re-reads
+// the applied versions, so a step already done by another instance is skipped.
+func MigrateTo(version int) error {
 	if dbConn == nil {
 		return errors.New("database connection is not initialized")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if version < 0 || (version > 0 && findMigration(migrations, version) == nil) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	for {
+		done := false
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			if err := lockMigrations(tx); err != nil {
+				return err
+			}
+
+			applied, err := appliedMigrations(tx)
+			if err != nil {
+				return err
+			}
+
+			for _, migration := range migrations {
+				if migration.Version <= version && applied[migration.Version] == nil {
+					return applyMigration(tx, migration)
+				}
+			}
+
+			for i := len(migrations) - 1; i >= 0; i-- {
+				if migrations[i].Version > version && applied[migrations[i].Version] != nil {
+					return revertMigration(tx, migrations[i])
+				}
+			}
+
+			for appliedVersion := range applied {
+				if appliedVersion > version && findMigration(migrations, appliedVersion) == nil {
+					return fmt.Errorf("migration %d is applied but not known to this build", appliedVersion)
+				}
+			}
+
+			done = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// GetMigrationStatus lists the known migrations together with the time they were applied.
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	if dbConn == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := createMigrationsTable(dbConn); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(dbConn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record := applied[migration.Version]; record != nil {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		if findMigration(migrations, record.Version) == nil {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, MigrationStatus{Version: record.Version, Name: record.Name, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// lockMigrations serialises migrations between instances. The Postgres lock is released
+// when the transaction ends. SQLite has no advisory locks, but sqliteDSN opens transactions
+// with BEGIN IMMEDIATE, so they take the database write lock as they begin, which
+// serialises them the same way.
+func lockMigrations(tx *gorm.DB) error {
+	if tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %v", err)
+		}
+	}
+	return createMigrationsTable(tx)
+}
+
+func createMigrationsTable(tx *gorm.DB) error {
+	timeType := "timestamptz"
+	if tx.Dialector.Name() == "sqlite" {
+		timeType = "datetime"
+	}
+
+	err := tx.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text NOT NULL, applied_at " + timeType + " NOT NULL)").Error
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+	return nil
+}
+
+func appliedMigrations(tx *gorm.DB) (map[int]*schemaMigration, error) {
+	var records []schemaMigration
+	if err := tx.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %v", err)
+	}
+
+	applied := make(map[int]*schemaMigration, len(records))
+	for i := range records {
+		applied[records[i].Version] = &records[i]
+	}
+	return applied, nil
+}
+
+func applyMigration(tx *gorm.DB, migration Migration) error {
+	if err := execMigrationSQL(tx, migration.Up); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	record := schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+	if err := tx.Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	fmt.Printf("Applied migration %04d_%s\n", migration.Version, migration.Name)
+	return nil
+}
 
+func revertMigration(tx *gorm.DB, migration Migration) error {
+	if err := execMigrationSQL(tx, migration.Down); err != nil {
+		return fmt.Errorf("failed to revert migration %04d_%s: %v", migration.Version, migration.Name, err)
 	}
 
-	migrateModels := []interface{}{
-		&models.Song{},
-		&models.SongDetail{},
+	if err := tx.Delete(&schemaMigration{}, migration.Version).Error; err != nil {
+		return fmt.Errorf("failed to remove migration %04d_%s: %v", migration.Version, migration.Name, err)
 	}
 
-	for _, model := range migrateModels {
-		err := dbConn.AutoMigrate(model)
+	fmt.Printf("Reverted migration %04d_%s\n", migration.Version, migration.Name)
+	return nil
+}
+
+func execMigrationSQL(tx *gorm.DB, sql string) error {
+	if strings.TrimSpace(sql) == "" {
+		return nil
+	}
+	return tx.Exec(sql).Error
+}
+
+// loadMigrations reads the embedded migrations of the connected database's dialect,
+// ordered by version.
+func loadMigrations() ([]Migration, error) {
+	if dbConn == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	dir := path.Join("migrations", dbConn.Dialector.Name())
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %v", dbConn.Dialector.Name(), err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		var direction string
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction, name = "up", strings.TrimSuffix(name, ".up.sql")
+		case strings.HasSuffix(name, ".down.sql"):
+			direction, name = "down", strings.TrimSuffix(name, ".down.sql")
+		default:
+			continue
+		}
+
+		prefix, title, found := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		data, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
 		if err != nil {
-			return fmt.Errorf("failed to migrate %T: %v", model, err)
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		} else if migration.Name != title {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, title)
+		}
+		if direction == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", migration.Version, migration.Name)
 		}
+		migrations = append(migrations, *migration)
 	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
 
+func findMigration(migrations []Migration, version int) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
+	}
 	return nil
 }
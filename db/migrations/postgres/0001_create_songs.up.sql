@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS songs (
+    id bigserial PRIMARY KEY,
+    "group" text,
+    song text,
+    release_date text,
+    text text,
+    link text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS song_details (
+    song text,
+    "group" text,
+    release_date text,
+    text text,
+    link text
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS song_field_provenances (
+    id bigserial PRIMARY KEY,
+    song_id bigint,
+    field text,
+    source text,
+    response_hash text,
+    recorded_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_field_provenance ON song_field_provenances (song_id, field);
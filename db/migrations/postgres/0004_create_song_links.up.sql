@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS song_links (
+    id bigserial PRIMARY KEY,
+    song_id bigint,
+    platform text,
+    url text,
+    platform_id text,
+    status_code bigint,
+    last_error text,
+    last_checked_at timestamptz,
+    failure_streak bigint,
+    created_at timestamptz,
+    CONSTRAINT fk_songs_links FOREIGN KEY (song_id) REFERENCES songs (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_links_song_url ON song_links (song_id, url);
+CREATE INDEX IF NOT EXISTS idx_song_links_failure_streak ON song_links (failure_streak);
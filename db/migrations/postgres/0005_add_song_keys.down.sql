@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_songs_group_song_key;
+
+ALTER TABLE songs DROP COLUMN IF EXISTS song_key;
+ALTER TABLE songs DROP COLUMN IF EXISTS group_key;
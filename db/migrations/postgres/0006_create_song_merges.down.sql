@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS song_merges;
+DROP TABLE IF EXISTS song_redirects;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS song_redirects (
+    from_id bigint PRIMARY KEY,
+    to_id bigint NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_redirects_to_id ON song_redirects (to_id);
+
+CREATE TABLE IF NOT EXISTS song_merges (
+    id bigserial PRIMARY KEY,
+    target_id bigint NOT NULL,
+    source_ids text,
+    fields text,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_merges_target_id ON song_merges (target_id);
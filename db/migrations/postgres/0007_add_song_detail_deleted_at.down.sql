@@ -0,0 +1,5 @@
+DELETE FROM song_details WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_song_details_deleted_at;
+
+ALTER TABLE song_details DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE songs DROP COLUMN IF EXISTS updated_by;
+
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
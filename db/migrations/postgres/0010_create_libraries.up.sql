@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS libraries (
+    id bigserial PRIMARY KEY,
+    slug text NOT NULL,
+    name text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_libraries_slug ON libraries (slug);
+
+-- Every song stored so far moves to the default library.
+INSERT INTO libraries (id, slug, name, created_at, updated_at) VALUES (1, 'default', 'Default', now(), now())
+    ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('libraries', 'id'), (SELECT MAX(id) FROM libraries));
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS library_id bigint NOT NULL DEFAULT 1
+    CONSTRAINT fk_songs_library REFERENCES libraries (id);
+CREATE INDEX IF NOT EXISTS idx_songs_library_id ON songs (library_id);
+
+-- Group and title are unique within a library only.
+DROP INDEX IF EXISTS idx_songs_group_song_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_library_group_song_key ON songs (library_id, group_key, song_key)
+    WHERE deleted_at IS NULL AND group_key <> '';
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS library_id bigint
+    CONSTRAINT fk_api_keys_library REFERENCES libraries (id);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS library_id bigint
+    CONSTRAINT fk_users_library REFERENCES libraries (id);
@@ -0,0 +1,9 @@
+-- Requests per client, route class and UTC day, counted for the daily quotas.
+CREATE TABLE IF NOT EXISTS quota_usages (
+    client text NOT NULL,
+    class text NOT NULL,
+    day text NOT NULL,
+    requests integer NOT NULL DEFAULT 0,
+    updated_at timestamptz,
+    PRIMARY KEY (client, class, day)
+);
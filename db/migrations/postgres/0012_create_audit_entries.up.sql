@@ -0,0 +1,34 @@
+-- Append-only log of mutating requests. Each entry's hash covers its content and the hash of
+-- the entry before it; the trigger turns away any attempt to change or remove entries.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    actor text NOT NULL DEFAULT '',
+    client_ip text NOT NULL DEFAULT '',
+    method text NOT NULL,
+    route text NOT NULL DEFAULT '',
+    path text NOT NULL DEFAULT '',
+    library_id bigint NOT NULL DEFAULT 0,
+    song_id bigint,
+    "before" text,
+    "after" text,
+    status integer NOT NULL,
+    error text NOT NULL DEFAULT '',
+    prev_hash text NOT NULL DEFAULT '',
+    hash text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_song_id ON audit_entries (song_id);
+
+CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries;
+CREATE TRIGGER audit_entries_append_only
+    BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW EXECUTE FUNCTION audit_entries_append_only();
@@ -0,0 +1,2 @@
+-- BCP 47 tag of the language of the lyrics, empty when unknown.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS language text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_song_details_group_song;
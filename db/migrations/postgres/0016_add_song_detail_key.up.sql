@@ -0,0 +1,9 @@
+-- At most one active song detail per group and title. Duplicates stored before the index
+-- existed are soft deleted first, keeping the row written last.
+UPDATE song_details AS d SET deleted_at = now()
+WHERE d.deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM song_details AS o
+    WHERE o.deleted_at IS NULL AND o."group" = d."group" AND o.song = d.song AND o.ctid > d.ctid
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_details_group_song ON song_details ("group", song) WHERE deleted_at IS NULL;
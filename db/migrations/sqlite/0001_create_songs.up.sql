@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS songs (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    "group" text,
+    song text,
+    release_date text,
+    text text,
+    link text,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at);
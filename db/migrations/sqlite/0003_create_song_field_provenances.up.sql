@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS song_field_provenances (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    song_id integer,
+    field text,
+    source text,
+    response_hash text,
+    recorded_at datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_field_provenance ON song_field_provenances (song_id, field);
@@ -0,0 +1,7 @@
+-- The keys are filled by the application (see models.NormalizeKey). Rows that still have an
+-- empty key are left out of the index until `go run ./cmd/dedup -backfill` fills them.
+ALTER TABLE songs ADD COLUMN group_key text NOT NULL DEFAULT '';
+ALTER TABLE songs ADD COLUMN song_key text NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song_key ON songs (group_key, song_key)
+    WHERE deleted_at IS NULL AND group_key <> '';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS song_redirects (
+    from_id integer PRIMARY KEY,
+    to_id integer NOT NULL,
+    created_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_redirects_to_id ON song_redirects (to_id);
+
+CREATE TABLE IF NOT EXISTS song_merges (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    target_id integer NOT NULL,
+    source_ids text,
+    fields text,
+    created_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_merges_target_id ON song_merges (target_id);
@@ -0,0 +1,5 @@
+-- Song details are soft deleted like songs, so a removed entry stops being served by
+-- /API/info but stays in the table.
+ALTER TABLE song_details ADD COLUMN deleted_at datetime;
+
+CREATE INDEX IF NOT EXISTS idx_song_details_deleted_at ON song_details (deleted_at);
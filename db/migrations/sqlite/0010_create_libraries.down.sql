@@ -0,0 +1,11 @@
+-- Fails if two libraries hold a song with the same group and title.
+DROP INDEX IF EXISTS idx_songs_library_group_song_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song_key ON songs (group_key, song_key)
+    WHERE deleted_at IS NULL AND group_key <> '';
+
+ALTER TABLE users DROP COLUMN library_id;
+ALTER TABLE api_keys DROP COLUMN library_id;
+DROP INDEX IF EXISTS idx_songs_library_id;
+ALTER TABLE songs DROP COLUMN library_id;
+
+DROP TABLE IF EXISTS libraries;
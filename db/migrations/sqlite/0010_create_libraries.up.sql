@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS libraries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    slug text NOT NULL,
+    name text NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_libraries_slug ON libraries (slug);
+
+-- Every song stored so far moves to the default library.
+INSERT OR IGNORE INTO libraries (id, slug, name, created_at, updated_at) VALUES (1, 'default', 'Default', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+-- SQLite can neither add a column that references another table and has a default nor drop
+-- a column that references another table, so the library columns go without foreign keys.
+ALTER TABLE songs ADD COLUMN library_id integer NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_songs_library_id ON songs (library_id);
+
+-- Group and title are unique within a library only.
+DROP INDEX IF EXISTS idx_songs_group_song_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_library_group_song_key ON songs (library_id, group_key, song_key)
+    WHERE deleted_at IS NULL AND group_key <> '';
+
+ALTER TABLE api_keys ADD COLUMN library_id integer;
+ALTER TABLE users ADD COLUMN library_id integer;
@@ -0,0 +1,33 @@
+-- Append-only log of mutating requests. Each entry's hash covers its content and the hash of
+-- the entry before it; the triggers turn away any attempt to change or remove entries.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime NOT NULL,
+    actor text NOT NULL DEFAULT '',
+    client_ip text NOT NULL DEFAULT '',
+    method text NOT NULL,
+    route text NOT NULL DEFAULT '',
+    path text NOT NULL DEFAULT '',
+    library_id integer NOT NULL DEFAULT 0,
+    song_id integer,
+    "before" text,
+    "after" text,
+    status integer NOT NULL,
+    error text NOT NULL DEFAULT '',
+    prev_hash text NOT NULL DEFAULT '',
+    hash text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_song_id ON audit_entries (song_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_update BEFORE UPDATE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete BEFORE DELETE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
@@ -0,0 +1,15 @@
+-- First responses to requests sent with an Idempotency-Key, replayed to retries until they
+-- expire. A row without a status belongs to a request still being handled.
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    client text NOT NULL,
+    key text NOT NULL,
+    fingerprint text NOT NULL,
+    status integer NOT NULL DEFAULT 0,
+    headers text,
+    body blob,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    PRIMARY KEY (client, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records (expires_at);
@@ -0,0 +1,13 @@
+-- The hash of the last audit entry. Appending an entry updates this one row first, which
+-- locks it until the transaction ends, so concurrent appends queue up behind each other
+-- and each one links to the entry stored before it.
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+    id integer PRIMARY KEY CHECK (id = 1),
+    hash text NOT NULL DEFAULT ''
+);
+
+INSERT INTO audit_chain_head (id, hash)
+SELECT 1, COALESCE((SELECT hash FROM audit_entries ORDER BY id DESC LIMIT 1), '')
+-- WHERE true keeps SQLite from reading ON CONFLICT as part of the SELECT.
+WHERE true
+ON CONFLICT (id) DO NOTHING;
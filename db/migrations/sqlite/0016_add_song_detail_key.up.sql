@@ -0,0 +1,9 @@
+-- At most one active song detail per group and title. Duplicates stored before the index
+-- existed are soft deleted first, keeping the row written last.
+UPDATE song_details SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM song_details AS o
+    WHERE o.deleted_at IS NULL AND o."group" = song_details."group" AND o.song = song_details.song AND o.rowid > song_details.rowid
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_details_group_song ON song_details ("group", song) WHERE deleted_at IS NULL;
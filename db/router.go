@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"sync/atomic"
+	"time"
+)
+
+// Router sends writes to the primary and spreads reads that tolerate some staleness over
+// the read replicas. A replica that cannot be reached or lags further behind than allowed
+// is skipped until it catches up; with no usable replica reads go to the primary as well.
+type Router struct {
+	primary  *gorm.DB
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint32
+}
+
+type replica struct {
+	name    string
+	conn    *gorm.DB
+	healthy atomic.Bool
+}
+
+func newRouter(primary *gorm.DB, maxLag time.Duration) *Router {
+	return &Router{primary: primary, maxLag: maxLag}
+}
+
+func (r *Router) addReplica(name string, conn *gorm.DB) {
+	r.replicas = append(r.replicas, &replica{name: name, conn: conn})
+}
+
+// Writer returns the primary connection.
+func (r *Router) Writer() *gorm.DB {
+	return r.primary
+}
+
+// Reader returns the next healthy replica in turn, or the primary if there is none.
+func (r *Router) Reader() *gorm.DB {
+	if len(r.replicas) == 0 {
+		return r.primary
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		candidate := r.replicas[(int(start)+i)%len(r.replicas)]
+		if candidate.healthy.Load() {
+			return candidate.conn
+		}
+	}
+	return r.primary
+}
+
+// MonitorReplicas checks the replicas every interval until ctx is done.
+func (r *Router) MonitorReplicas(ctx context.Context, interval time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkReplicas(ctx)
+		}
+	}
+}
+
+func (r *Router) checkReplicas(ctx context.Context) {
+	for _, replica := range r.replicas {
+		healthy := r.checkReplica(ctx, replica)
+		if replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				logger.Info.Printf("[db.Router] Replica %s is back in use", replica.name)
+			} else {
+				logger.Warning.Printf("[db.Router] Replica %s is skipped, reads fall back", replica.name)
+			}
+		}
+	}
+}
+
+// checkReplica reports whether the replica answers and, when a maximum lag is set, has
+// replayed the primary's changes recently enough. A replica that has received no new WAL
+// is up to date however long ago the last transaction was.
+func (r *Router) checkReplica(ctx context.Context, replica *replica) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var lagSeconds float64
+	err := replica.conn.WithContext(ctx).Raw(`SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&lagSeconds).Error
+	if err != nil {
+		logger.Error.Printf("[db.Router] Failed to check replica %s: %v", replica.name, err)
+		return false
+	}
+
+	if r.maxLag > 0 && time.Duration(lagSeconds*float64(time.Second)) > r.maxLag {
+		logger.Warning.Printf("[db.Router] Replica %s lags %.1fs behind", replica.name, lagSeconds)
+		return false
+	}
+	return true
+}
+
+func (r *Router) close() error {
+	for _, replica := range r.replicas {
+		sqlDB, err := replica.conn.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"song-library/configs"
+)
+
+// IsolationLevel returns the transaction isolation level set by isolation_level in
+// database_params; an empty value keeps the database default.
+func IsolationLevel() (sql.IsolationLevel, error) {
+	switch level := configs.AppSettings.DatabaseParams.IsolationLevel; level {
+	case "":
+		return sql.LevelDefault, nil
+	case "read_committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable_read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("unsupported isolation level %q", level)
+	}
+}
@@ -0,0 +1,101 @@
+// Package i18n holds the message catalogues responses are localised with. Each catalogue
+// is a flat JSON object of texts by key, stored as <language>.json, such as ru.json, in
+// the directory named by locale_params.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.org/x/text/language"
+	"os"
+	"path/filepath"
+	"song-library/models"
+	"strings"
+)
+
+type Catalogues struct {
+	defaultLanguage string
+	languages       []string // defaultLanguage first
+	matcher         language.Matcher
+	texts           map[string]map[string]string
+}
+
+// Load reads every catalogue in params.Directory. The catalogue of the default language
+// must be among them.
+func Load(params models.LocaleParams) (*Catalogues, error) {
+	files, err := filepath.Glob(filepath.Join(params.Directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalogues{
+		defaultLanguage: params.DefaultLanguage,
+		languages:       []string{params.DefaultLanguage},
+		texts:           make(map[string]map[string]string),
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("catalogue %s is not named after a language: %v", file, err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		texts := make(map[string]string)
+		if err := json.Unmarshal(data, &texts); err != nil {
+			return nil, fmt.Errorf("couldn't decode catalogue %s: %v", file, err)
+		}
+
+		name = tag.String()
+		c.texts[name] = texts
+		if name != params.DefaultLanguage {
+			c.languages = append(c.languages, name)
+		}
+	}
+	if c.texts[params.DefaultLanguage] == nil {
+		return nil, fmt.Errorf("no catalogue for the default language %q in %s", params.DefaultLanguage, params.Directory)
+	}
+
+	tags := make([]language.Tag, len(c.languages))
+	for i, name := range c.languages {
+		tags[i] = language.Make(name)
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// Negotiate picks the catalogue that best fits an Accept-Language header, such as
+// "ru-RU,ru;q=0.9,en;q=0.8", and returns its language. Without a header, or if no
+// catalogue fits, it is the default language.
+func (c *Catalogues) Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return c.defaultLanguage
+	}
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return c.defaultLanguage
+	}
+	return c.languages[index]
+}
+
+// Text returns the text of key in lang, or in the default language if the catalogue of
+// lang lacks it, or else the key itself. replacements are placeholder and value pairs,
+// such as "{id}", "5".
+func (c *Catalogues) Text(lang, key string, replacements ...string) string {
+	if c == nil {
+		return key
+	}
+	text, ok := c.texts[lang][key]
+	if !ok {
+		if text, ok = c.texts[c.defaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(replacements) > 0 {
+		text = strings.NewReplacer(replacements...).Replace(text)
+	}
+	return text
+}
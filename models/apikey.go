@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// API key scopes. ScopeAdmin grants every other scope as well.
+const (
+	ScopeSongsRead  = "songs:read"
+	ScopeSongsWrite = "songs:write"
+	ScopeSongsPurge = "songs:purge"
+	ScopeAdmin      = "admin"
+)
+
+// Scopes lists every scope an API key can be given.
+var Scopes = []string{ScopeSongsRead, ScopeSongsWrite, ScopeSongsPurge, ScopeAdmin}
+
+// APIKey is a key a client authenticates with. Only the SHA-256 hash of the key is stored;
+// Prefix keeps its first characters so keys can be told apart in listings. A key with a
+// LibraryID only reaches the songs of that library.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `json:"name"`
+	LibraryID  *uint      `json:"library_id,omitempty"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `gorm:"uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants the given scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return hasScope(k.Scopes, scope)
+}
+
+// Principal returns the key as the caller of a request.
+func (k *APIKey) Principal() *Principal {
+	principal := &Principal{Name: "key:" + k.Name, APIKeyID: k.ID, Scopes: k.Scopes}
+	if k.LibraryID != nil {
+		principal.LibraryID = *k.LibraryID
+	}
+	return principal
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key can still be used at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type NewAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=255"`
+	LibraryID *uint      `json:"library_id,omitempty"` // Library the key is limited to; every library if left out
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IssuedAPIKey is returned when a key is created or rotated. Key is the plain key, which
+// is shown this one time only.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records one mutating request: who sent it, what it targeted and how it
+// ended. Entries are only ever appended. Each is sealed with a hash over its content and
+// the hash of the entry before it, so changing or removing an entry breaks the chain from
+// that entry on.
+type AuditEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	Actor     string    `gorm:"index" json:"actor"`
+	ClientIP  string    `json:"client_ip"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"` // route pattern, such as /songs/:id
+	Path      string    `json:"path"`
+	LibraryID uint      `json:"library_id,omitempty"`
+	SongID    *uint     `gorm:"index" json:"song_id,omitempty"`
+	// Before and After hold the target song as it was before and after the request; After
+	// is empty once the song is gone.
+	Before   *Song  `gorm:"serializer:json" json:"before,omitempty"`
+	After    *Song  `gorm:"serializer:json" json:"after,omitempty"`
+	Status   int    `json:"status"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// Seal chains the entry onto the entry whose hash is prevHash ("" for the first entry).
+func (e *AuditEntry) Seal(prevHash string) {
+	e.PrevHash = prevHash
+	e.Hash = e.digest()
+}
+
+// Intact reports whether the entry still matches its hash and follows the entry whose
+// hash is prevHash.
+func (e *AuditEntry) Intact(prevHash string) bool {
+	return e.PrevHash == prevHash && e.Hash == e.digest()
+}
+
+// digest hashes everything but the ID, which the database assigns, and the hash itself.
+func (e AuditEntry) digest() string {
+	e.ID, e.Hash = 0, ""
+	e.CreatedAt = e.CreatedAt.UTC()
+	payload, _ := json.Marshal(e)
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter selects audit entries; zero fields match everything.
+type AuditFilter struct {
+	Actor     string
+	Method    string
+	Route     string
+	SongID    uint
+	LibraryID uint
+	From      *time.Time
+	To        *time.Time
+	Page      int
+	Limit     int
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log.
+type AuditVerification struct {
+	Intact  bool `json:"intact"`
+	Checked int  `json:"checked"`
+	// BrokenAt is the first entry that no longer matches its hash or its predecessor.
+	BrokenAt *uint  `json:"broken_at,omitempty"`
+	LastHash string `json:"last_hash,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	"song-library/models"
+	"testing"
+	"time"
+)
+
+func sealedEntry() models.AuditEntry {
+	songID := uint(7)
+	entry := models.AuditEntry{
+		ID:        3,
+		CreatedAt: time.Date(2026, 10, 19, 12, 30, 0, 123456000, time.UTC),
+		Actor:     "editor",
+		ClientIP:  "203.0.113.5",
+		Method:    "PUT",
+		Route:     "/songs/:id",
+		Path:      "/songs/7",
+		LibraryID: 1,
+		SongID:    &songID,
+		Before:    &models.Song{ID: 7, Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"},
+		After:     &models.Song{ID: 7, Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom\n\nThe PR transmissions will resume"},
+		Status:    200,
+	}
+	entry.Seal("previous-hash")
+	return entry
+}
+
+func TestAuditEntrySeal(t *testing.T) {
+	entry := sealedEntry()
+	if entry.PrevHash != "previous-hash" || len(entry.Hash) != 64 {
+		t.Fatalf("Seal left PrevHash %q and Hash %q", entry.PrevHash, entry.Hash)
+	}
+	if !entry.Intact("previous-hash") {
+		t.Error("Intact of a sealed entry = false")
+	}
+	if entry.Intact("other-hash") {
+		t.Error("Intact after another predecessor = true")
+	}
+	if again := sealedEntry(); again.Hash != entry.Hash {
+		t.Errorf("Seal of the same entry = %q, then %q", entry.Hash, again.Hash)
+	}
+}
+
+func TestAuditEntryDigestIgnoresIDAndZone(t *testing.T) {
+	entry := sealedEntry()
+	entry.ID = 99
+	entry.CreatedAt = entry.CreatedAt.In(time.FixedZone("MSK", 3*60*60))
+	if !entry.Intact("previous-hash") {
+		t.Error("Intact after a new ID or time zone = false, want true")
+	}
+}
+
+func TestAuditEntryIntactNoticesChanges(t *testing.T) {
+	for name, change := range map[string]func(e *models.AuditEntry){
+		"created_at": func(e *models.AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+		"actor":      func(e *models.AuditEntry) { e.Actor = "admin" },
+		"client_ip":  func(e *models.AuditEntry) { e.ClientIP = "198.51.100.1" },
+		"method":     func(e *models.AuditEntry) { e.Method = "DELETE" },
+		"route":      func(e *models.AuditEntry) { e.Route = "/songs/hard/:id" },
+		"path":       func(e *models.AuditEntry) { e.Path = "/songs/8" },
+		"library_id": func(e *models.AuditEntry) { e.LibraryID = 2 },
+		"song_id":    func(e *models.AuditEntry) { e.SongID = nil },
+		"before":     func(e *models.AuditEntry) { e.Before.Text = "" },
+		"after":      func(e *models.AuditEntry) { e.After = nil },
+		"status":     func(e *models.AuditEntry) { e.Status = 500 },
+		"error":      func(e *models.AuditEntry) { e.Error = "ErrSongNotFound" },
+		"prev_hash":  func(e *models.AuditEntry) { e.PrevHash = "" },
+		"hash":       func(e *models.AuditEntry) { e.Hash = e.Hash[1:] + "0" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			entry := sealedEntry()
+			change(&entry)
+			if entry.Intact("previous-hash") {
+				t.Errorf("Intact after changing %s = true", name)
+			}
+		})
+	}
+}
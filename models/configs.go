@@ -1,9 +1,16 @@
 package models
 
 type AppConfig struct {
-	LogParams      LogParams      `json:"log_params"`      // Log parameters
-	AppParams      AppParams      `json:"app_params"`      // Application parameters
-	PostgresParams PostgresParams `json:"postgres_params"` // PostgreSQL database parameters
+	LogParams         LogParams         `json:"log_params"`         // Log parameters
+	AppParams         AppParams         `json:"app_params"`         // Application parameters
+	DatabaseParams    DatabaseParams    `json:"database_params"`    // Database parameters
+	LinkCheckParams   LinkCheckParams   `json:"link_check_params"`  // Link health checker parameters
+	TimeoutParams     TimeoutParams     `json:"timeout_params"`     // Request deadline parameters
+	AuthParams        AuthParams        `json:"auth_params"`        // Authentication parameters
+	RateLimitParams   RateLimitParams   `json:"rate_limit_params"`  // Rate limit and quota parameters
+	LocaleParams      LocaleParams      `json:"locale_params"`      // Response language parameters
+	IdempotencyParams IdempotencyParams `json:"idempotency_params"` // Idempotency-Key parameters
+	BatchParams       BatchParams       `json:"batch_params"`       // Song batch parameters
 }
 
 type LogParams struct {
@@ -28,9 +35,80 @@ type AppParams struct {
 	ApiURL     string `json:"api_url"`      // API URL
 }
 
-type PostgresParams struct {
-	Host     string `json:"host"`     // Database host
-	Port     string `json:"port"`     // Database port
-	User     string `json:"user"`     // Database username
-	Database string `json:"database"` // Database name
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+type DatabaseParams struct {
+	Driver         string `json:"driver"`          // Storage backend: postgres (default), sqlite or memory
+	Host           string `json:"host"`            // Database host (postgres)
+	Port           string `json:"port"`            // Database port (postgres)
+	User           string `json:"user"`            // Database username (postgres)
+	Database       string `json:"database"`        // Database name (postgres)
+	SQLitePath     string `json:"sqlite_path"`     // Database file, or ":memory:" (sqlite)
+	IsolationLevel string `json:"isolation_level"` // Transaction isolation: read_committed, repeatable_read or serializable (postgres)
+
+	MaxOpenConns           int `json:"max_open_conns"`             // Maximum number of open connections, 0 for unlimited
+	MaxIdleConns           int `json:"max_idle_conns"`             // Maximum number of idle connections kept in the pool
+	ConnMaxLifetimeSeconds int `json:"conn_max_lifetime_seconds"`  // Time after which a connection is closed, 0 for never
+	ConnMaxIdleTimeSeconds int `json:"conn_max_idle_time_seconds"` // Idle time after which a connection is closed, 0 for never
+
+	Replicas                    []ReplicaParams `json:"replicas"`                       // Read replicas for listing, search and lyrics queries (postgres)
+	MaxReplicaLagSeconds        int             `json:"max_replica_lag_seconds"`        // Replication lag beyond which a replica is skipped, 0 for no limit
+	ReplicaCheckIntervalSeconds int             `json:"replica_check_interval_seconds"` // Pause between two checks of the replicas
+}
+
+type ReplicaParams struct {
+	Host string `json:"host"` // Replica host
+	Port string `json:"port"` // Replica port
+}
+
+type LinkCheckParams struct {
+	Enabled             bool `json:"enabled"`               // Whether the link health checker runs
+	IntervalMinutes     int  `json:"interval_minutes"`      // Pause between two checks of all links
+	Concurrency         int  `json:"concurrency"`           // Maximum number of links probed at once
+	PerHostIntervalMs   int  `json:"per_host_interval_ms"`  // Minimum pause between two requests to the same host
+	TimeoutSeconds      int  `json:"timeout_seconds"`       // Timeout of a single probe
+	BrokenAfterFailures int  `json:"broken_after_failures"` // Failed checks in a row after which a link is broken
+	ReenrichBrokenSongs bool `json:"reenrich_broken_songs"` // Whether songs with a newly broken link are re-enriched
+}
+
+type TimeoutParams struct {
+	DefaultMs int            `json:"default_ms"` // Deadline of a request in milliseconds, 0 for none
+	Endpoints map[string]int `json:"endpoints"`  // Deadlines of single endpoints, keyed by method and route, e.g. "POST /songs/"
+}
+
+type AuthParams struct {
+	Enabled               bool   `json:"enabled"`                  // Whether requests need an API key or access token; when off every route is open
+	JWTAlgorithm          string `json:"jwt_algorithm"`            // Signing algorithm of access tokens: HS256 (secret from JWT_SECRET) or RS256
+	JWTPrivateKeyFile     string `json:"jwt_private_key_file"`     // PEM encoded RSA private key (RS256)
+	AccessTokenTTLMinutes int    `json:"access_token_ttl_minutes"` // Lifetime of access tokens
+	RefreshTokenTTLHours  int    `json:"refresh_token_ttl_hours"`  // Lifetime of refresh tokens
+	AllowRegistration     bool   `json:"allow_registration"`       // Whether anyone may register an account with the viewer role
+}
+
+type RateLimitParams struct {
+	Enabled bool                      `json:"enabled"` // Whether requests are rate limited
+	Classes map[string]RateLimitClass `json:"classes"` // Budgets per route class: read, write and enrichment
+}
+
+type RateLimitClass struct {
+	RequestsPerMinute int `json:"requests_per_minute"` // Sustained rate per client, 0 for no limit
+	Burst             int `json:"burst"`               // Requests a client may make at once; defaults to requests_per_minute
+	DailyQuota        int `json:"daily_quota"`         // Requests per client and UTC day, 0 for no quota
+}
+
+type LocaleParams struct {
+	Directory       string `json:"directory"`        // Directory of the message catalogues, one <language>.json per language
+	DefaultLanguage string `json:"default_language"` // Language used when the client accepts none of the catalogues, and for texts missing from one
+}
+
+type IdempotencyParams struct {
+	WindowHours int `json:"window_hours"` // How long the first response to an Idempotency-Key is replayed, 0 to ignore the header
+}
+
+type BatchParams struct {
+	MaxOperations int `json:"max_operations"` // Most operations a song batch may hold
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is the first response to a request sent with an Idempotency-Key, kept
+// so retries of the request get the same response. Until the request has been answered
+// Status is 0.
+type IdempotencyRecord struct {
+	Client      string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey"`
+	Fingerprint string // Hash of the method, path, library and body of the request
+	Status      int
+	Headers     map[string]string `gorm:"serializer:json"` // Content-Type and Content-Language of the response
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response to the request is known.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
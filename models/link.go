@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	PlatformYouTube    = "youtube"
+	PlatformSpotify    = "spotify"
+	PlatformAppleMusic = "apple_music"
+	PlatformBandcamp   = "bandcamp"
+	PlatformSoundCloud = "soundcloud"
+	PlatformGeneric    = "generic"
+)
+
+type SongLink struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	SongID        uint       `gorm:"uniqueIndex:idx_song_links_song_url" json:"song_id"`
+	Platform      string     `json:"platform"`
+	URL           string     `gorm:"uniqueIndex:idx_song_links_song_url" json:"url"`
+	PlatformID    string     `json:"platform_id,omitempty"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	FailureStreak int        `gorm:"index" json:"failure_streak"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type BrokenLink struct {
+	SongLink
+	Group string `json:"group"`
+	Song  string `json:"song"`
+}
+
+type NewSongLinkRequest struct {
+	URL string `json:"url" binding:"required,url,max=2048"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// SongRedirect points the ID of a song that was merged away to the song that survived.
+type SongRedirect struct {
+	FromID    uint      `gorm:"primaryKey;autoIncrement:false" json:"from_id"`
+	ToID      uint      `gorm:"index" json:"to_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SongMerge records a merge: which songs were folded into the target and which song each
+// chosen field was taken from.
+type SongMerge struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	TargetID  uint            `gorm:"index" json:"target_id"`
+	SourceIDs []uint          `gorm:"serializer:json" json:"source_ids"`
+	Fields    map[string]uint `gorm:"serializer:json" json:"fields"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type MergeSongsRequest struct {
+	TargetID  uint            `json:"target_id" binding:"required"`
+	SourceIDs []uint          `json:"source_ids" binding:"required,min=1,dive,required"`
+	Fields    map[string]uint `json:"fields"`
+}
+
+// SongGroup is a group of active songs of one library, by normalised group name, and the
+// lowest ID among its songs.
+type SongGroup struct {
+	LibraryID   uint
+	GroupKey    string
+	FirstSongID uint
+}
+
+// DuplicateCluster is a group of songs that are likely the same recording. LyricsSimilarity
+// is the lowest Jaccard similarity of the lyrics between any two songs of the cluster, and
+// is missing when fewer than two of them have lyrics.
+type DuplicateCluster struct {
+	Group            string   `json:"group"`
+	Title            string   `json:"title"`
+	LyricsSimilarity *float64 `json:"lyrics_similarity,omitempty"`
+	Songs            []Song   `json:"songs"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	SourceProvider = "provider"
+	SourceManual   = "manual"
+	SourceImport   = "import"
+)
+
+const (
+	FieldReleaseDate = "release_date"
+	FieldText        = "text"
+	FieldLink        = "link"
+)
+
+type SongFieldProvenance struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SongID       uint      `gorm:"uniqueIndex:idx_song_field_provenance" json:"song_id"`
+	Field        string    `gorm:"uniqueIndex:idx_song_field_provenance" json:"field"`
+	Source       string    `json:"source"`
+	ResponseHash string    `json:"response_hash,omitempty"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Route classes, each with its own budget in rate_limit_params. Enrichment covers the
+// routes that call the metadata provider.
+const (
+	RateClassRead       = "read"
+	RateClassWrite      = "write"
+	RateClassEnrichment = "enrichment"
+)
+
+// QuotaUsage counts the requests a client made in one route class on one UTC day, for the
+// daily quotas.
+type QuotaUsage struct {
+	Client    string `gorm:"primaryKey"`
+	Class     string `gorm:"primaryKey"`
+	Day       string `gorm:"primaryKey"` // UTC date as 2006-01-02
+	Requests  int
+	UpdatedAt time.Time
+}
@@ -1,28 +1,100 @@
 package models
 
-import "time"
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// ReleaseDateLayout is the format of release dates returned by the metadata provider.
+const ReleaseDateLayout = "02.01.2006"
 
 type Song struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	Group       string     `json:"group"`
-	Song        string     `json:"song"`
-	ReleaseDate string     `json:"release_date"`
-	Text        string     `json:"text"`
-	Link        string     `json:"link"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	LibraryID   uint           `gorm:"index" json:"library_id"`
+	Group       string         `json:"group"`
+	Song        string         `json:"song"`
+	GroupKey    string         `json:"-"`
+	SongKey     string         `json:"-"`
+	ReleaseDate string         `json:"release_date"`
+	Text        string         `json:"text"`
+	Link        string         `json:"link"`
+	Language    string         `json:"language,omitempty"` // BCP 47 tag of the language of the lyrics, such as en
+	Links       []SongLink     `gorm:"foreignKey:SongID" json:"links,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	UpdatedBy   string         `json:"updated_by,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"string" format:"date-time"`
+}
+
+// SetKeys fills the normalised group and title that the unique index on songs, per library,
+// is built on.
+func (s *Song) SetKeys() {
+	s.GroupKey = NormalizeKey(s.Group)
+	s.SongKey = NormalizeKey(s.Song)
+}
+
+// NormalizeKey folds a group or song title for comparison: compatibility forms are
+// unified (NFKC), case is folded and runs of whitespace collapse to one space, so
+// "ＡＢＢＡ " and "abba" give the same key.
+func NormalizeKey(value string) string {
+	folded := norm.NFKC.String(cases.Fold().String(norm.NFKC.String(value)))
+	return strings.Join(strings.Fields(folded), " ")
 }
 
 type SongDetail struct {
-	Song        string `json:"song"`
-	Group       string `json:"group"`
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Song        string         `json:"song" binding:"required,max=255"`
+	Group       string         `json:"group" binding:"required,max=255"`
+	ReleaseDate string         `json:"releaseDate" binding:"omitempty,datetime=02.01.2006"`
+	Text        string         `json:"text" binding:"max=50000"`
+	Link        string         `json:"link" binding:"omitempty,url,max=2048"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
 }
 
 type NewSongRequest struct {
-	Group string `json:"group"`
-	Song  string `json:"song"`
+	Group    string `json:"group" binding:"required,max=255"`
+	Song     string `json:"song" binding:"required,max=255"`
+	Language string `json:"language,omitempty" binding:"omitempty,bcp47_language_tag"`
+}
+
+// UpdateSongRequest replaces the editable fields of a song; fields left out are cleared.
+type UpdateSongRequest struct {
+	Group       string `json:"group" binding:"required,max=255"`
+	Song        string `json:"song" binding:"required,max=255"`
+	ReleaseDate string `json:"release_date,omitempty" binding:"omitempty,datetime=02.01.2006"` // DD.MM.YYYY
+	Text        string `json:"text,omitempty" binding:"max=50000"`
+	Link        string `json:"link,omitempty" binding:"omitempty,url,max=2048"`
+	Language    string `json:"language,omitempty" binding:"omitempty,bcp47_language_tag"`
+}
+
+// Operations of a song batch.
+const (
+	BatchCreate  = "create"
+	BatchUpdate  = "update"
+	BatchPatch   = "patch"
+	BatchDelete  = "delete"
+	BatchRestore = "restore"
+)
+
+// SongBatchRequest lists changes to songs to be made at once, either atomically, so that
+// none is kept unless all succeed, or each on its own.
+type SongBatchRequest struct {
+	Atomic     bool                 `json:"atomic"`
+	Operations []SongBatchOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+// SongBatchOperation is one change of a batch. create adds a song with the given fields;
+// update replaces the fields of song id, clearing those left out; patch changes only the
+// fields it names; delete soft deletes song id and restore undoes that.
+type SongBatchOperation struct {
+	Op          string  `json:"op" binding:"required,oneof=create update patch delete restore"`
+	ID          uint    `json:"id,omitempty"`
+	Group       *string `json:"group,omitempty" binding:"omitnil,min=1,max=255"`
+	Song        *string `json:"song,omitempty" binding:"omitnil,min=1,max=255"`
+	ReleaseDate *string `json:"release_date,omitempty" binding:"omitempty,datetime=02.01.2006"` // DD.MM.YYYY
+	Text        *string `json:"text,omitempty" binding:"omitempty,max=50000"`
+	Link        *string `json:"link,omitempty" binding:"omitempty,url,max=2048"`
+	Language    *string `json:"language,omitempty" binding:"omitempty,bcp47_language_tag"`
 }
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// User roles. A role grants the scopes listed for it in RoleScopes.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var RoleScopes = map[string][]string{
+	RoleViewer: {ScopeSongsRead},
+	RoleEditor: {ScopeSongsRead, ScopeSongsWrite},
+	RoleAdmin:  {ScopeAdmin},
+}
+
+// User is a person signing in with a password. A user with a LibraryID only reaches the
+// songs of that library.
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Username     string    `gorm:"uniqueIndex" json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	LibraryID    *uint     `json:"library_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RefreshToken is a long-lived token a user trades for a new access token. Only its
+// SHA-256 hash is stored, and it is revoked once used.
+type RefreshToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type Credentials struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type NewUserRequest struct {
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Role      string `json:"role" binding:"required"`
+	LibraryID *uint  `json:"library_id,omitempty"` // Library the user is limited to; every library if left out
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Lifetime of the access token in seconds
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Principal is the authenticated caller of a request, a user or an API key. LibraryID is
+// the library it is limited to, or 0 if it may use any.
+type Principal struct {
+	Name      string
+	UserID    uint
+	APIKeyID  uint
+	LibraryID uint
+	Scopes    []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return hasScope(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// ActorFromContext returns the name recorded as the author of a change made in ctx, or an
+// empty string when the request is not authenticated.
+func ActorFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.Name
+	}
+	return ""
+}
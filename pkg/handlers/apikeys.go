@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+)
+
+// GetAPIKeys godoc
+// @Summary      List API keys
+// @Description  Lists all API keys, including revoked ones. The keys themselves are never returned, only their prefixes.
+// @Tags         API keys
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.APIKey  "API keys"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "API key lacks the admin scope"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /api-keys [get]
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	logger.Info.Printf("[handlers.GetAPIKeys] Client IP: %s - Request to list API keys", c.ClientIP())
+
+	keys, err := h.apiKeys.GetAPIKeys(c.Request.Context())
+	if err != nil {
+		logger.Error.Printf("[handlers.GetAPIKeys] Error listing API keys: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Creates an API key with the given scopes (songs:read, songs:write, songs:purge, admin), optionally limited to one library; keys limited to a library cannot have the admin scope. The key is part of the response only this once.
+// @Tags         API keys
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        key  body      models.NewAPIKeyRequest  true  "Name, scopes and optional expiry"
+// @Success      201  {object}  models.IssuedAPIKey  "The new key"
+// @Failure      400  {object}  ProblemDetails "Invalid name, scope, library or expiry"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "API key lacks the admin scope"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	logger.Info.Printf("[handlers.CreateAPIKey] Client IP: %s - Request to create an API key", c.ClientIP())
+
+	var request models.NewAPIKeyRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.CreateAPIKey] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	key, err := h.apiKeys.CreateAPIKey(c.Request.Context(), request)
+	if err != nil {
+		logger.Error.Printf("[handlers.CreateAPIKey] Error creating API key: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	logger.Info.Printf("[handlers.CreateAPIKey] Created API key %d (%s)", key.ID, key.Prefix)
+	c.JSON(http.StatusCreated, key)
+}
+
+// RotateAPIKey godoc
+// @Summary      Rotate an API key
+// @Description  Replaces the secret of an API key, keeping its name and scopes. The old key stops working immediately; the new one is part of the response only this once.
+// @Tags         API keys
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "API key ID"
+// @Success      200  {object}  models.IssuedAPIKey  "The rotated key"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "API key lacks the admin scope"
+// @Failure      404  {object}  ProblemDetails "API key not found or revoked"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /api-keys/{id}/rotate [post]
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	idParam := c.Param("id")
+	logger.Info.Printf("[handlers.RotateAPIKey] Client IP: %s - Request to rotate API key %s", c.ClientIP(), idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.RotateAPIKey] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	key, err := h.apiKeys.RotateAPIKey(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Error.Printf("[handlers.RotateAPIKey] Error rotating API key: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Revokes an API key for good. It stays in the list, marked with the time it was revoked.
+// @Tags         API keys
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "API key ID"
+// @Success      200  {object}  DefaultResponse  "API key revoked"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "API key lacks the admin scope"
+// @Failure      404  {object}  ProblemDetails "API key not found or already revoked"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	idParam := c.Param("id")
+	logger.Info.Printf("[handlers.RevokeAPIKey] Client IP: %s - Request to revoke API key %s", c.ClientIP(), idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.RevokeAPIKey] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	if err := h.apiKeys.RevokeAPIKey(c.Request.Context(), uint(id)); err != nil {
+		logger.Error.Printf("[handlers.RevokeAPIKey] Error revoking API key: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.api_key_revoked")))
+}
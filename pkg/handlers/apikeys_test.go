@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"reflect"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"testing"
+	"time"
+)
+
+const authChallenge = `Bearer realm="song-library"`
+
+// createAPIKey creates a key through the API as admin and returns it with its secret.
+func createAPIKey(t *testing.T, router *gin.Engine, body string) models.IssuedAPIKey {
+	t.Helper()
+	response := serve(t, router, http.MethodPost, "/api-keys", adminKey, body)
+	var key models.IssuedAPIKey
+	if err := json.Unmarshal(response.Body.Bytes(), &key); err != nil || response.Code != http.StatusCreated {
+		t.Fatalf("POST /api-keys %s = %d %s", body, response.Code, response.Body)
+	}
+	return key
+}
+
+// seedSong adds a song, so listing songs succeeds.
+func seedSong(t *testing.T, repos repository.Repositories) {
+	t.Helper()
+	if err := repos.Songs.AddSong(context.Background(), &models.Song{Group: "Muse", Song: "Uprising"}); err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+}
+
+func getAPIKey(t *testing.T, repos repository.Repositories, id uint) *models.APIKey {
+	t.Helper()
+	key, err := repos.APIKeys.GetAPIKeyByID(context.Background(), id)
+	if err != nil || key == nil {
+		t.Fatalf("GetAPIKeyByID(%d) = %+v, %v", id, key, err)
+	}
+	return key
+}
+
+func TestRequireScope(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+
+	for _, test := range []struct {
+		name, method, target, key string
+		status                    int
+	}{
+		{"no key", http.MethodGet, "/songs/", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/songs/", "sl_unknown-key", http.StatusUnauthorized},
+		{"read", http.MethodGet, "/songs/", writerKey, http.StatusOK},
+		{"admin only", http.MethodGet, "/api-keys", writerKey, http.StatusForbidden},
+		{"purge", http.MethodDelete, "/songs/hard/1", writerKey, http.StatusForbidden},
+		{"admin", http.MethodGet, "/api-keys", adminKey, http.StatusOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response := serve(t, router, test.method, test.target, test.key, "")
+			if response.Code != test.status {
+				t.Fatalf("%s %s = %d %s, want %d", test.method, test.target, response.Code, response.Body, test.status)
+			}
+			// Only a missing or invalid credential is a challenge to authenticate.
+			challenge := response.Header().Get("WWW-Authenticate")
+			if want := test.status == http.StatusUnauthorized; (challenge == authChallenge) != want {
+				t.Errorf("WWW-Authenticate = %q with status %d", challenge, response.Code)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRefusesInactiveKeys(t *testing.T) {
+	router, repos := newTestServer(t)
+	revoked := createAPIKey(t, router, `{"name":"revoked","scopes":["songs:read"]}`)
+	expired := createAPIKey(t, router, `{"name":"expired","scopes":["songs:read"],"expires_at":"`+time.Now().Add(time.Hour).Format(time.RFC3339)+`"}`)
+
+	if response := serve(t, router, http.MethodDelete, fmt.Sprintf("/api-keys/%d", revoked.ID), adminKey, ""); response.Code != http.StatusNoContent && response.Code != http.StatusOK {
+		t.Fatalf("DELETE /api-keys/%d = %d %s", revoked.ID, response.Code, response.Body)
+	}
+	key := getAPIKey(t, repos, expired.ID)
+	past := time.Now().Add(-time.Second)
+	key.ExpiresAt = &past
+	if err := repos.APIKeys.UpdateAPIKey(context.Background(), key); err != nil {
+		t.Fatalf("UpdateAPIKey: %v", err)
+	}
+
+	for name, plain := range map[string]string{"revoked": revoked.Key, "expired": expired.Key} {
+		response := serve(t, router, http.MethodGet, "/songs/", plain, "")
+		if response.Code != http.StatusUnauthorized || response.Header().Get("WWW-Authenticate") != authChallenge {
+			t.Errorf("GET /songs/ with the %s key = %d %v, want 401 with a challenge", name, response.Code, response.Header())
+		}
+	}
+}
+
+func TestAuthenticateTouchesKeysOncePerMinute(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	issued := createAPIKey(t, router, `{"name":"reader","scopes":["songs:read"]}`)
+	if key := getAPIKey(t, repos, issued.ID); key.LastUsedAt != nil {
+		t.Fatalf("LastUsedAt of an unused key = %v", key.LastUsedAt)
+	}
+
+	get := func() {
+		t.Helper()
+		if response := serve(t, router, http.MethodGet, "/songs/", issued.Key, ""); response.Code != http.StatusOK {
+			t.Fatalf("GET /songs/ = %d %s", response.Code, response.Body)
+		}
+	}
+	get()
+	first := getAPIKey(t, repos, issued.ID).LastUsedAt
+	if first == nil || time.Since(*first) > time.Minute {
+		t.Fatalf("LastUsedAt after the first use = %v, want now", first)
+	}
+	get()
+	if second := getAPIKey(t, repos, issued.ID).LastUsedAt; second == nil || !second.Equal(*first) {
+		t.Errorf("LastUsedAt after a second use within a minute = %v, want it left at %v", second, first)
+	}
+
+	earlier := time.Now().Add(-61 * time.Second)
+	if err := repos.APIKeys.TouchAPIKey(context.Background(), issued.ID, earlier); err != nil {
+		t.Fatalf("TouchAPIKey: %v", err)
+	}
+	get()
+	if third := getAPIKey(t, repos, issued.ID).LastUsedAt; third == nil || !third.After(earlier.Add(time.Minute)) {
+		t.Errorf("LastUsedAt after a use a minute later = %v, want now", third)
+	}
+}
+
+func TestRotateAPIKeyInvalidatesOldSecret(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	old := createAPIKey(t, router, `{"name":"rotated","scopes":["songs:read"]}`)
+
+	response := serve(t, router, http.MethodPost, fmt.Sprintf("/api-keys/%d/rotate", old.ID), adminKey, "")
+	var rotated models.IssuedAPIKey
+	if err := json.Unmarshal(response.Body.Bytes(), &rotated); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("POST /api-keys/%d/rotate = %d %s", old.ID, response.Code, response.Body)
+	}
+	if rotated.ID != old.ID || rotated.Key == old.Key || rotated.Prefix != rotated.Key[:8] {
+		t.Errorf("rotated key = %+v, want key %d with a new secret and its prefix", rotated, old.ID)
+	}
+
+	if response := serve(t, router, http.MethodGet, "/songs/", old.Key, ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("GET /songs/ with the old secret = %d, want 401", response.Code)
+	}
+	if response := serve(t, router, http.MethodGet, "/songs/", rotated.Key, ""); response.Code != http.StatusOK {
+		t.Errorf("GET /songs/ with the new secret = %d %s, want 200", response.Code, response.Body)
+	}
+}
+
+func TestCreateAPIKeyScopes(t *testing.T) {
+	router, _ := newTestServer(t)
+
+	key := createAPIKey(t, router, `{"name":"writer","scopes":["songs:read","songs:write","songs:read"]}`)
+	if want := []string{models.ScopeSongsRead, models.ScopeSongsWrite}; !reflect.DeepEqual(key.Scopes, want) {
+		t.Errorf("scopes = %v, want %v without the repeat", key.Scopes, want)
+	}
+
+	for _, body := range []string{
+		`{"name":"none","scopes":[]}`,
+		`{"name":"missing"}`,
+		`{"name":"unknown","scopes":["songs:read","songs:delete"]}`,
+		`{"name":"case","scopes":["ADMIN"]}`,
+		`{"name":"   ","scopes":["songs:read"]}`,
+		`{"name":"bound admin","scopes":["admin"],"library_id":1}`,
+		`{"name":"expired","scopes":["songs:read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+	} {
+		if response := serve(t, router, http.MethodPost, "/api-keys", adminKey, body); response.Code != http.StatusBadRequest {
+			t.Errorf("POST /api-keys %s = %d %s, want 400", body, response.Code, response.Body)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetAuditEntries godoc
+// @Summary      Get the audit log
+// @Description  Lists the audit log of mutating requests, newest first: who sent each request, from where, to which route, the target song before and after it, and the outcome.
+// @Tags         Audit
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        actor       query   string  false  "Caller, such as key:<name> or user:<username>"
+// @Param        method      query   string  false  "HTTP method"
+// @Param        route       query   string  false  "Route pattern, such as /songs/hard/:id"
+// @Param        song_id     query   int     false  "Target song ID"
+// @Param        library_id  query   int     false  "Library ID"
+// @Param        from        query   string  false  "Only entries at or after this time (RFC 3339)"
+// @Param        to          query   string  false  "Only entries before this time (RFC 3339)"
+// @Param        page        query   int     false  "Page number"  default(1)
+// @Param        limit       query   int     false  "Number of results per page"  default(10)
+// @Success      200  {array}   models.AuditEntry  "Audit entries"
+// @Failure      400  {object}  ProblemDetails "Invalid filter or pagination parameters"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "Caller lacks the admin scope"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /audit [get]
+func (h *Handler) GetAuditEntries(c *gin.Context) {
+	logger.Info.Printf("[handlers.GetAuditEntries] Client IP: %s - Request to read the audit log", c.ClientIP())
+
+	filter, err := auditFilter(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	entries, err := h.audit.GetAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetAuditEntries] Error reading the audit log: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyAuditChain godoc
+// @Summary      Verify the audit log
+// @Description  Recomputes the hash chain of the audit log from its first entry and reports the first entry that was changed, or follows a removed one.
+// @Tags         Audit
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.AuditVerification  "Result of the check"
+// @Failure      401  {object}  ProblemDetails "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails "Caller lacks the admin scope"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /audit/verify [get]
+func (h *Handler) VerifyAuditChain(c *gin.Context) {
+	logger.Info.Printf("[handlers.VerifyAuditChain] Client IP: %s - Request to verify the audit log", c.ClientIP())
+
+	result, err := h.audit.VerifyAuditChain(c.Request.Context())
+	if err != nil {
+		logger.Error.Printf("[handlers.VerifyAuditChain] Error verifying the audit log: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func auditFilter(c *gin.Context) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:  c.Query("actor"),
+		Method: strings.ToUpper(c.Query("method")),
+		Route:  c.Query("route"),
+		Page:   1,
+		Limit:  10,
+	}
+
+	var err error
+	if page := c.Query("page"); page != "" {
+		if filter.Page, err = strconv.Atoi(page); err != nil {
+			return filter, utils.ErrInvalidPaginationParams
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, utils.ErrInvalidPaginationParams
+		}
+	}
+
+	for param, id := range map[string]*uint{"song_id": &filter.SongID, "library_id": &filter.LibraryID} {
+		if value := c.Query(param); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				return filter, utils.ErrInvalidRequestParameter
+			}
+			*id = uint(parsed)
+		}
+	}
+	for param, t := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, utils.ErrInvalidRequestParameter
+			}
+			*t = &parsed
+		}
+	}
+	return filter, nil
+}
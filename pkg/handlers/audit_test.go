@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"testing"
+)
+
+// failingAudit is an audit log that cannot be written to.
+type failingAudit struct {
+	repository.AuditRepository
+}
+
+func (failingAudit) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return utils.ErrDatabaseConnectionFailed
+}
+
+func TestUnauditedRequestFails(t *testing.T) {
+	router, repos := newTestServer(t, func(repos *repository.Repositories) {
+		repos.Audit = failingAudit{repos.Audit}
+	})
+
+	response := serve(t, router, http.MethodPost, "/libraries", adminKey, `{"slug":"team","name":"Team"}`)
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusServiceUnavailable || problem.Code != utils.ErrDatabaseConnectionFailed.Error() {
+		t.Errorf("POST /libraries without an audit log = %d %s, want 503 %s", response.Code, response.Body, utils.ErrDatabaseConnectionFailed)
+	}
+	if got := response.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+	if _, err := repos.Libraries.GetLibraryBySlug(context.Background(), "team"); err != nil {
+		t.Errorf("GetLibraryBySlug: %v, want the library created before the audit failed", err)
+	}
+}
+
+func TestAuditedResponseIsReleased(t *testing.T) {
+	router, repos := newTestServer(t)
+
+	response := serve(t, router, http.MethodPost, "/libraries", adminKey, `{"slug":"team","name":"Team"}`)
+	if response.Code != http.StatusCreated || response.Header().Get("Content-Type") != "application/json; charset=utf-8" || response.Header().Get("X-Request-ID") == "" {
+		t.Fatalf("POST /libraries = %d %v %s, want 201 with its headers", response.Code, response.Header(), response.Body)
+	}
+	entries, err := repos.Audit.GetAuditEntriesAfterID(context.Background(), 0, 10)
+	if err != nil || len(entries) != 1 || entries[0].Status != http.StatusCreated {
+		t.Errorf("audit log = %+v, %v, want one entry with status 201", entries, err)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+)
+
+// ApplySongBatch godoc
+// @Summary      Change songs in a batch
+// @Description  Runs a list of operations on songs in order: create adds a song with the given fields as they are, without asking the metadata provider; update replaces the fields of song id, clearing those left out; patch changes only the fields it names; delete soft deletes song id and restore undoes that. With atomic set, nothing is kept unless every operation succeeds, and the others then report ErrBatchRolledBack; otherwise each operation is kept or fails on its own. The response lists the outcome of each operation in the order sent. Besides the entry for the request, every operation that is kept is added to the audit log with the song before and after it.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        batch  body    models.SongBatchRequest  true  "Operations to run"
+// @Param        Idempotency-Key  header  string  false  "Key under which retries of the request get the response to its first use"
+// @Success      200    {object}  SongBatchResponse  "Outcome of each operation"
+// @Failure      400    {object}  ProblemDetails "Invalid request body; errors lists each invalid operation"
+// @Failure      409    {object}  ProblemDetails "Idempotency-Key in use"
+// @Failure      413    {object}  ProblemDetails "More operations than batch_params allows"
+// @Failure      422    {object}  ProblemDetails "Idempotency-Key used before for a different request"
+// @Failure      429    {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/batch [post]
+func (h *Handler) ApplySongBatch(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.ApplySongBatch] Client IP: %s - Request to change songs in a batch", ip)
+
+	var batch models.SongBatchRequest
+	if err := bindJSON(c, &batch); err != nil {
+		logger.Error.Printf("[handlers.ApplySongBatch] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	request := models.AuditEntry{
+		Actor:     models.ActorFromContext(ctx),
+		ClientIP:  ip,
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		LibraryID: models.LibraryFromContext(ctx),
+	}
+	outcomes, err := h.songs.ApplySongBatch(ctx, batch, request)
+	if err != nil {
+		logger.Error.Printf("[handlers.ApplySongBatch] Error applying batch: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	response := SongBatchResponse{Atomic: batch.Atomic, Results: make([]SongBatchResult, len(outcomes))}
+	for i, outcome := range outcomes {
+		op := batch.Operations[i].Op
+		result := SongBatchResult{Op: op, ID: outcome.SongID, Status: http.StatusOK}
+		if op == models.BatchCreate {
+			result.Status = http.StatusCreated
+		}
+		if err := outcome.Err; err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = utils.ErrRequestTimeout
+			}
+			p, known := lookupProblem(err)
+			if !known {
+				logger.Error.Printf("[handlers.ApplySongBatch] Operation %d failed: %v", i, err)
+			}
+			code := p.err.Error()
+			result.Status = p.status
+			result.Code = code
+			result.Title = text(c, code+".title")
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results[i] = result
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,167 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"reflect"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/pkg/handlers"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strconv"
+	"testing"
+)
+
+// mixedBatch creates a song, patches song 1 and then creates a song that exists already.
+func mixedBatch(atomic bool) string {
+	return `{"atomic":` + strconv.FormatBool(atomic) + `,"operations":[
+		{"op":"create","group":"Muse","song":"Hysteria"},
+		{"op":"patch","id":1,"text":"Paranoia is in bloom"},
+		{"op":"create","group":"Muse","song":"Uprising"}
+	]}`
+}
+
+func applyBatch(t *testing.T, router *gin.Engine, body string) handlers.SongBatchResponse {
+	t.Helper()
+	response := serve(t, router, http.MethodPost, "/songs/batch", writerKey, body)
+	var batch handlers.SongBatchResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &batch); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("POST /songs/batch = %d %s", response.Code, response.Body)
+	}
+	return batch
+}
+
+// songAudits returns the audit entries of single songs, leaving out those of whole requests.
+func songAudits(t *testing.T, repos repository.Repositories) []models.AuditEntry {
+	t.Helper()
+	entries, err := repos.Audit.GetAuditEntriesAfterID(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatalf("GetAuditEntriesAfterID: %v", err)
+	}
+	var songs []models.AuditEntry
+	for _, entry := range entries {
+		if entry.SongID != nil {
+			songs = append(songs, entry)
+		}
+	}
+	return songs
+}
+
+func TestAtomicBatchRollsBack(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	ctx := context.Background()
+
+	batch := applyBatch(t, router, mixedBatch(true))
+	rolledBack := utils.ErrBatchRolledBack.Error()
+	want := []handlers.SongBatchResult{
+		{Op: models.BatchCreate, Status: http.StatusFailedDependency, Code: rolledBack},
+		{Op: models.BatchPatch, ID: 1, Status: http.StatusFailedDependency, Code: rolledBack},
+		{Op: models.BatchCreate, Status: http.StatusConflict, Code: utils.ErrSongAlreadyExists.Error()},
+	}
+	for i := range batch.Results {
+		batch.Results[i].Title = ""
+	}
+	if !batch.Atomic || batch.Succeeded != 0 || batch.Failed != 3 || !reflect.DeepEqual(batch.Results, want) {
+		t.Errorf("atomic batch = %+v, want %+v", batch, want)
+	}
+
+	if exists, err := repos.Songs.SongExists(ctx, "Muse", "Hysteria"); err != nil || exists {
+		t.Errorf("SongExists(Hysteria) = %v, %v, want the created song rolled back", exists, err)
+	}
+	if song, err := repos.Songs.GetSongByID(ctx, 1, false); err != nil || song.Text != "" {
+		t.Errorf("song 1 = %+v, %v, want the patch rolled back", song, err)
+	}
+	if audits := songAudits(t, repos); len(audits) != 0 {
+		t.Errorf("audit entries of songs = %+v, want none for a rolled back batch", audits)
+	}
+}
+
+func TestBatchOperationsStandAlone(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	ctx := context.Background()
+
+	batch := applyBatch(t, router, mixedBatch(false))
+	if batch.Atomic || batch.Succeeded != 2 || batch.Failed != 1 {
+		t.Fatalf("batch = %+v, want 2 operations kept and 1 failed", batch)
+	}
+	created, patched, failed := batch.Results[0], batch.Results[1], batch.Results[2]
+	if created.Status != http.StatusCreated || created.ID == 0 || patched.Status != http.StatusOK || patched.ID != 1 {
+		t.Errorf("results = %+v, want the song created and song 1 patched", batch.Results)
+	}
+	if failed.Status != http.StatusConflict || failed.Code != utils.ErrSongAlreadyExists.Error() || failed.Title == "" {
+		t.Errorf("result of the duplicate = %+v, want 409 %s with a title", failed, utils.ErrSongAlreadyExists)
+	}
+
+	if song, err := repos.Songs.GetSongByID(ctx, created.ID, false); err != nil || song.Song != "Hysteria" {
+		t.Errorf("created song = %+v, %v, want Hysteria", song, err)
+	}
+	if song, err := repos.Songs.GetSongByID(ctx, 1, false); err != nil || song.Text != "Paranoia is in bloom" {
+		t.Errorf("song 1 = %+v, %v, want it patched", song, err)
+	}
+
+	// Each operation kept has an entry with the song before and after it.
+	audits := songAudits(t, repos)
+	if len(audits) != 2 {
+		t.Fatalf("audit entries of songs = %+v, want one per operation kept", audits)
+	}
+	create, patch := audits[0], audits[1]
+	if *create.SongID != created.ID || create.Status != http.StatusCreated || create.Before != nil || create.After == nil || create.Route != "/songs/batch" {
+		t.Errorf("audit entry of the create = %+v", create)
+	}
+	if *patch.SongID != 1 || patch.Status != http.StatusOK || patch.Before == nil || patch.Before.Text != "" || patch.After == nil || patch.After.Text != "Paranoia is in bloom" {
+		t.Errorf("audit entry of the patch = %+v, want the song before and after", patch)
+	}
+}
+
+func TestBatchReportsInvalidOperations(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+
+	response := serve(t, router, http.MethodPost, "/songs/batch", writerKey, `{"operations":[
+		{"op":"delete"},
+		{"op":"create","id":5,"group":"Muse","song":"Hysteria"},
+		{"op":"patch","id":1},
+		{"op":"patch","id":1,"link":"http://localhost/song"},
+		{"op":"restore","id":1}
+	]}`)
+	var problem handlers.ProblemDetails
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusBadRequest {
+		t.Fatalf("POST /songs/batch = %d %s, want 400", response.Code, response.Body)
+	}
+	var got []utils.FieldError
+	for _, field := range problem.Errors {
+		got = append(got, utils.FieldError{Pointer: field.Pointer, Code: field.Code})
+	}
+	want := []utils.FieldError{
+		{Pointer: "/operations/0/id", Code: utils.ErrMissingRequiredField.Error()},
+		{Pointer: "/operations/1/id", Code: utils.ErrInvalidRequestBody.Error()},
+		{Pointer: "/operations/2", Code: utils.ErrMissingRequiredField.Error()},
+		{Pointer: "/operations/3/link", Code: utils.ErrInvalidLink.Error()},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %+v, want %+v", got, want)
+	}
+	if audits := songAudits(t, repos); len(audits) != 0 {
+		t.Errorf("audit entries of songs = %+v, want none when no operation ran", audits)
+	}
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	configs.AppSettings.BatchParams = models.BatchParams{MaxOperations: 2}
+
+	response := serve(t, router, http.MethodPost, "/songs/batch", writerKey, mixedBatch(false))
+	var problem handlers.ProblemDetails
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusRequestEntityTooLarge || problem.Code != utils.ErrBatchTooLarge.Error() {
+		t.Errorf("POST /songs/batch of 3 operations = %d %s, want 413 %s", response.Code, response.Body, utils.ErrBatchTooLarge)
+	}
+	if exists, err := repos.Songs.SongExists(context.Background(), "Muse", "Hysteria"); err != nil || exists {
+		t.Errorf("SongExists(Hysteria) = %v, %v, want no operation run", exists, err)
+	}
+}
@@ -1,58 +1,62 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"song-library/i18n"
 	"song-library/logger"
 	"song-library/utils"
 )
 
+// handleError answers the request with the problem document of err (see problems).
 func handleError(c *gin.Context, err error) {
-	var statusCode int
-	var errorResponse ErrorResponse
+	// Kept on the context for the middleware, such as recordAudit.
+	_ = c.Error(err)
 
-	switch {
-	case errors.Is(err, utils.ErrSongAlreadyExists),
-		errors.Is(err, utils.ErrInvalidSongData),
-		errors.Is(err, utils.ErrSongDeleteFailed),
-		errors.Is(err, utils.ErrSongUpdateFailed),
-		errors.Is(err, utils.ErrInvalidGroup),
-		errors.Is(err, utils.ErrInvalidSongTitle),
-		errors.Is(err, utils.ErrInvalidReleaseDate),
-		errors.Is(err, utils.ErrInvalidText),
-		errors.Is(err, utils.ErrInvalidLink),
-		errors.Is(err, utils.ErrFailedToFetchSongInfoFromAPI),
-		errors.Is(err, utils.ErrDatabaseConnectionFailed),
-		errors.Is(err, utils.ErrInvalidPaginationParams),
-		errors.Is(err, utils.ErrFailedToParseJSON),
-		errors.Is(err, utils.ErrUnexpectedError),
-		errors.Is(err, utils.ErrInvalidID),
-		errors.Is(err, utils.ErrFailedToGenerateSwagger),
-		errors.Is(err, utils.ErrInvalidRequestBody),
-		errors.Is(err, utils.ErrSongAlreadyExists),
-		errors.Is(err, utils.ErrInvalidRequestParameter),
-		errors.Is(err, utils.ErrMissingRequiredField):
-		statusCode = http.StatusBadRequest
-		errorResponse = NewErrorResponse(err.Error())
-
-	case errors.Is(err, utils.ErrSongNotFoundInDatabase),
-		errors.Is(err, utils.ErrSongNotFound),
-		errors.Is(err, utils.ErrGroupNotFound),
-		errors.Is(err, utils.ErrSongNotFoundInDatabase):
-		statusCode = http.StatusNotFound
-		errorResponse = NewErrorResponse(err.Error())
-
-	case errors.Is(err, utils.ErrSongDeleteFailed),
-		errors.Is(err, utils.ErrSongUpdateFailed):
-		statusCode = http.StatusInternalServerError
-		errorResponse = NewErrorResponse(err.Error())
+	// Whatever failed, it failed because the request ran out of time.
+	if errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		logger.Error.Printf("Request deadline exceeded: %v", err)
+		err = utils.ErrRequestTimeout
+	}
 
-	default:
+	p, known := lookupProblem(err)
+	if !known {
 		logger.Error.Printf("Standard error occurred: %v", err)
-		statusCode = http.StatusInternalServerError
-		errorResponse = NewErrorResponse(utils.ErrUnexpectedError.Error())
 	}
 
-	c.JSON(statusCode, errorResponse)
+	code := p.err.Error()
+	response := ProblemDetails{
+		Type:     "/errors#" + code,
+		Title:    text(c, code+".title"),
+		Status:   p.status,
+		Detail:   text(c, code+".detail"),
+		Instance: c.GetString(requestIDKey),
+		Code:     code,
+	}
+	// Wrapped errors tell which part of the request failed, which is for the log only.
+	if known && err.Error() != code {
+		logger.Warning.Printf("[handlers.handleError]: %s: %v", code, err)
+	}
+	var invalid *utils.ValidationError
+	if errors.As(err, &invalid) {
+		// Fields without a detail of their own are described by their code.
+		response.Errors = make([]utils.FieldError, len(invalid.Fields))
+		for i, field := range invalid.Fields {
+			if field.Detail == "" {
+				field.Detail = text(c, field.Code+".detail")
+			}
+			response.Errors[i] = field
+		}
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.JSON(p.status, response)
+}
+
+// text returns the text of key in the language negotiated for the request by localise.
+// replacements are placeholder and value pairs, such as "{id}", "5".
+func text(c *gin.Context, key string, replacements ...string) string {
+	catalogues, _ := c.Value(cataloguesKey).(*i18n.Catalogues)
+	return catalogues.Text(c.GetString(languageKey), key, replacements...)
 }
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+	"testing"
+)
+
+// flakySongs fails the first lookup of a song, as a database that is briefly away would.
+type flakySongs struct {
+	repository.SongRepository
+	failed bool
+}
+
+func (r *flakySongs) SongExists(ctx context.Context, group, song string) (bool, error) {
+	if !r.failed {
+		r.failed = true
+		return false, utils.ErrDatabaseConnectionFailed
+	}
+	return r.SongRepository.SongExists(ctx, group, song)
+}
+
+// addSong posts a new song with the given API key and Idempotency-Key.
+func addSong(t *testing.T, router *gin.Engine, key, idempotencyKey, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/songs/", strings.NewReader(body))
+	request.Header.Set("X-API-Key", key)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Idempotency-Key", idempotencyKey)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAddSongRetryIsReplayed(t *testing.T) {
+	router, repos := newTestServer(t)
+	configs.AppSettings.IdempotencyParams = models.IdempotencyParams{WindowHours: 24}
+	const body = `{"group":"Muse","song":"Uprising"}`
+
+	first := addSong(t, router, writerKey, "add-uprising", body)
+	if first.Code != http.StatusOK || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("POST /songs/ = %d %v %s", first.Code, first.Header(), first.Body)
+	}
+	retry := addSong(t, router, writerKey, "add-uprising", body)
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retried POST /songs/ = %d %v %s, want the first response replayed", retry.Code, retry.Header(), retry.Body)
+	}
+	if got := retry.Header().Get("Content-Type"); got != first.Header().Get("Content-Type") {
+		t.Errorf("replayed Content-Type = %q, want %q", got, first.Header().Get("Content-Type"))
+	}
+	if songs, err := repos.Songs.GetSongs(context.Background(), "Muse", "", true, 1, 10); err != nil || len(songs) != 1 {
+		t.Errorf("songs of Muse = %+v, %v, want the one song added once", songs, err)
+	}
+
+	if response := addSong(t, router, writerKey, "add-uprising", `{"group":"Muse","song":"Hysteria"}`); response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST /songs/ reusing the key for another song = %d %s, want 422", response.Code, response.Body)
+	}
+	// The key of another client is a key of its own, so the request runs and finds the song.
+	if response := addSong(t, router, adminKey, "add-uprising", body); response.Code != http.StatusConflict || response.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("POST /songs/ by another client = %d %s, want 409 from running it", response.Code, response.Body)
+	}
+}
+
+func TestAddSongRetryRunsAfterServerError(t *testing.T) {
+	router, _ := newTestServer(t, func(repos *repository.Repositories) {
+		repos.Songs = &flakySongs{SongRepository: repos.Songs}
+	})
+	configs.AppSettings.IdempotencyParams = models.IdempotencyParams{WindowHours: 24}
+	const body = `{"group":"Muse","song":"Uprising"}`
+
+	if response := addSong(t, router, writerKey, "add-uprising", body); response.Code != http.StatusServiceUnavailable {
+		t.Fatalf("POST /songs/ = %d %s, want 503", response.Code, response.Body)
+	}
+	retry := addSong(t, router, writerKey, "add-uprising", body)
+	if retry.Code != http.StatusOK || retry.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retried POST /songs/ = %d %v %s, want it run again", retry.Code, retry.Header(), retry.Body)
+	}
+}
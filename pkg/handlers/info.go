@@ -4,7 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"song-library/logger"
-	services "song-library/pkg/services"
+	"song-library/models"
 	"song-library/utils"
 )
 
@@ -17,10 +17,12 @@ import (
 // @Param        group  query   string  true  "Group name (artist/band)"
 // @Param        song   query   string  true  "Song title"
 // @Success      200    {object}  models.SongDetail  "Successfully retrieved song details"
-// @Failure      400    {object}  ErrorResponse      "Invalid request parameters"
-// @Failure      500    {object}  ErrorResponse      "Internal server error"
+// @Failure      400    {object}  ProblemDetails     "Invalid request parameters"
+// @Failure      429    {object}  ProblemDetails     "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails     "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /API/info [get]
-func ApiInfo(c *gin.Context) {
+func (h *Handler) ApiInfo(c *gin.Context) {
 	ip := c.ClientIP()
 	logger.Info.Printf("[handlers.ApiInfo]: Client with ip: %s request to get InfoSong", ip)
 
@@ -33,7 +35,7 @@ func ApiInfo(c *gin.Context) {
 		return
 	}
 
-	songDetail, err := services.GetSongDetail(group, song)
+	songDetail, err := h.details.GetSongDetail(c.Request.Context(), group, song)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -41,3 +43,165 @@ func ApiInfo(c *gin.Context) {
 	logger.Info.Printf("[handlers.ApiInfo]: Client with ip: %s, successufly to get InfoSong", ip)
 	c.JSON(http.StatusOK, songDetail)
 }
+
+// AddSongDetail godoc
+// @Summary      Add song details
+// @Description  Adds song details to the reference table served by the built-in metadata provider. Needs the admin scope, since song details are shared by all libraries.
+// @Tags         API
+// @Accept       json
+// @Produce      json
+// @Param        detail  body      models.SongDetail  true  "Song details"
+// @Param        Idempotency-Key  header    string  false  "Key under which retries of the request get the response to its first use"
+// @Success      200     {object}  DefaultResponse    "Song details added successfully"
+// @Failure      400     {object}  ProblemDetails     "Invalid request body"
+// @Failure      409     {object}  ProblemDetails     "Song details already exist or Idempotency-Key in use"
+// @Failure      422     {object}  ProblemDetails     "Idempotency-Key used before for a different request"
+// @Failure      401     {object}  ProblemDetails     "Missing or invalid API key"
+// @Failure      403     {object}  ProblemDetails     "Caller lacks the admin scope"
+// @Failure      429     {object}  ProblemDetails     "Rate limit or daily quota exceeded"
+// @Failure      500     {object}  ProblemDetails     "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /API/info [post]
+func (h *Handler) AddSongDetail(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.AddSongDetail]: Client with ip: %s request to add song details", ip)
+
+	var songDetail models.SongDetail
+	if err := bindJSON(c, &songDetail); err != nil {
+		logger.Error.Printf("[handlers.AddSongDetail]: Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	if err := h.details.AddSongDetail(c.Request.Context(), &songDetail); err != nil {
+		logger.Error.Printf("[handlers.AddSongDetail]: Error adding song details: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.song_details_added")))
+}
+
+// UpdateSongDetail godoc
+// @Summary      Update song details
+// @Description  Replaces the song details identified by the group and song title. Needs the admin scope, since song details are shared by all libraries.
+// @Tags         API
+// @Accept       json
+// @Produce      json
+// @Param        group   query     string             true  "Group name (artist/band)"
+// @Param        song    query     string             true  "Song title"
+// @Param        detail  body      models.SongDetail  true  "Updated song details"
+// @Success      200     {object}  DefaultResponse    "Song details updated successfully"
+// @Failure      400     {object}  ProblemDetails     "Invalid request parameters or body"
+// @Failure      404     {object}  ProblemDetails     "Song details not found"
+// @Failure      401     {object}  ProblemDetails     "Missing or invalid API key"
+// @Failure      403     {object}  ProblemDetails     "Caller lacks the admin scope"
+// @Failure      429     {object}  ProblemDetails     "Rate limit or daily quota exceeded"
+// @Failure      500     {object}  ProblemDetails     "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /API/info [put]
+func (h *Handler) UpdateSongDetail(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.UpdateSongDetail]: Client with ip: %s request to update song details", ip)
+
+	group := c.Query("group")
+	song := c.Query("song")
+	if group == "" || song == "" {
+		logger.Error.Printf("[handlers.UpdateSongDetail]: Error Invalid Request Parameter")
+		handleError(c, utils.ErrInvalidRequestParameter)
+		return
+	}
+
+	var songDetail models.SongDetail
+	if err := bindJSON(c, &songDetail); err != nil {
+		logger.Error.Printf("[handlers.UpdateSongDetail]: Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	if err := h.details.UpdateSongDetail(c.Request.Context(), group, song, &songDetail); err != nil {
+		logger.Error.Printf("[handlers.UpdateSongDetail]: Error updating song details: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.song_details_updated")))
+}
+
+// DeleteSongDetail godoc
+// @Summary      Delete song details
+// @Description  Deletes the song details identified by the group and song title. Needs the admin scope, since song details are shared by all libraries.
+// @Tags         API
+// @Accept       json
+// @Produce      json
+// @Param        group  query     string           true  "Group name (artist/band)"
+// @Param        song   query     string           true  "Song title"
+// @Success      200    {object}  DefaultResponse  "Song details deleted successfully"
+// @Failure      400    {object}  ProblemDetails   "Invalid request parameters"
+// @Failure      404    {object}  ProblemDetails   "Song details not found"
+// @Failure      401    {object}  ProblemDetails   "Missing or invalid API key"
+// @Failure      403    {object}  ProblemDetails   "Caller lacks the admin scope"
+// @Failure      429    {object}  ProblemDetails   "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails   "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /API/info [delete]
+func (h *Handler) DeleteSongDetail(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.DeleteSongDetail]: Client with ip: %s request to delete song details", ip)
+
+	group := c.Query("group")
+	song := c.Query("song")
+	if group == "" || song == "" {
+		logger.Error.Printf("[handlers.DeleteSongDetail]: Error Invalid Request Parameter")
+		handleError(c, utils.ErrInvalidRequestParameter)
+		return
+	}
+
+	if err := h.details.DeleteSongDetail(c.Request.Context(), group, song); err != nil {
+		logger.Error.Printf("[handlers.DeleteSongDetail]: Error deleting song details: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.song_details_deleted")))
+}
+
+// BulkUpsertSongDetails godoc
+// @Summary      Bulk load song details
+// @Description  Creates or replaces a list of song details in one transaction. Nothing is written if any item is invalid. Needs the admin scope, since song details are shared by all libraries.
+// @Tags         API
+// @Accept       json
+// @Produce      json
+// @Param        details  body      []models.SongDetail  true  "Song details to load"
+// @Param        Idempotency-Key  header    string  false  "Key under which retries of the request get the response to its first use"
+// @Success      200      {object}  BulkResponse         "Number of created and updated song details"
+// @Failure      400      {object}  ProblemDetails       "Invalid request body or song details; errors lists each invalid one"
+// @Failure      409      {object}  ProblemDetails       "Song details listed twice or Idempotency-Key in use"
+// @Failure      422      {object}  ProblemDetails       "Idempotency-Key used before for a different request"
+// @Failure      401      {object}  ProblemDetails       "Missing or invalid API key"
+// @Failure      403      {object}  ProblemDetails       "Caller lacks the admin scope"
+// @Failure      429      {object}  ProblemDetails       "Rate limit or daily quota exceeded"
+// @Failure      500      {object}  ProblemDetails       "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /API/info/bulk [post]
+func (h *Handler) BulkUpsertSongDetails(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.BulkUpsertSongDetails]: Client with ip: %s request to bulk load song details", ip)
+
+	var songDetails []models.SongDetail
+	if err := bindJSON(c, &songDetails); err != nil {
+		logger.Error.Printf("[handlers.BulkUpsertSongDetails]: Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	created, updated, err := h.details.BulkUpsertSongDetails(c.Request.Context(), songDetails)
+	if err != nil {
+		logger.Error.Printf("[handlers.BulkUpsertSongDetails]: Error loading song details: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	logger.Info.Printf("[handlers.BulkUpsertSongDetails]: Client with ip: %s, created %d and updated %d song details", ip, created, updated)
+	c.JSON(http.StatusOK, BulkResponse{Created: created, Updated: updated})
+}
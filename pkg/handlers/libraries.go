@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+)
+
+// GetLibraries godoc
+// @Summary      List libraries
+// @Description  Lists all libraries. Requests pick one with the X-Library header, sending its slug.
+// @Tags         Libraries
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.Library  "Libraries"
+// @Failure      401  {object}  ProblemDetails  "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails  "Caller lacks the admin scope"
+// @Failure      500  {object}  ProblemDetails  "Internal server error"
+// @Router       /libraries [get]
+func (h *Handler) GetLibraries(c *gin.Context) {
+	logger.Info.Printf("[handlers.GetLibraries] Client IP: %s - Request to list libraries", c.ClientIP())
+
+	libraries, err := h.libraries.GetLibraries(c.Request.Context())
+	if err != nil {
+		logger.Error.Printf("[handlers.GetLibraries] Error listing libraries: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, libraries)
+}
+
+// CreateLibrary godoc
+// @Summary      Create a library
+// @Description  Creates an empty library. The slug is made of lowercase letters, digits and dashes, up to 63 characters.
+// @Tags         Libraries
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        library  body      models.NewLibraryRequest  true  "Slug and name"
+// @Param        Idempotency-Key  header    string  false  "Key under which retries of the request get the response to its first use"
+// @Success      201  {object}  models.Library  "The new library"
+// @Failure      400  {object}  ProblemDetails  "Invalid slug or name"
+// @Failure      409  {object}  ProblemDetails  "Slug is taken or Idempotency-Key in use"
+// @Failure      401  {object}  ProblemDetails  "Missing or invalid API key"
+// @Failure      403  {object}  ProblemDetails  "Caller lacks the admin scope"
+// @Failure      422  {object}  ProblemDetails  "Idempotency-Key used before for a different request"
+// @Failure      500  {object}  ProblemDetails  "Internal server error"
+// @Router       /libraries [post]
+func (h *Handler) CreateLibrary(c *gin.Context) {
+	logger.Info.Printf("[handlers.CreateLibrary] Client IP: %s - Request to create a library", c.ClientIP())
+
+	var request models.NewLibraryRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.CreateLibrary] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	library, err := h.libraries.CreateLibrary(c.Request.Context(), request)
+	if err != nil {
+		logger.Error.Printf("[handlers.CreateLibrary] Error creating library: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, library)
+}
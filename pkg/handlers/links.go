@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+)
+
+// GetSongLinks godoc
+// @Summary      Get song links
+// @Description  Retrieves all platform links of a song.
+// @Tags         Links
+// @Accept       json
+// @Produce      json
+// @Param        id   path    int     true  "Song ID"
+// @Success      200  {array}   models.SongLink  "Links of the song"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/{id}/links [get]
+func (h *Handler) GetSongLinks(c *gin.Context) {
+	ip := c.ClientIP()
+	idParam := c.Param("id")
+
+	logger.Info.Printf("[handlers.GetSongLinks] Client IP: %s - Request to get links of song by id: %s", ip, idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongLinks] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	links, err := h.songs.GetSongLinks(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongLinks] Error getting links: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// AddSongLink godoc
+// @Summary      Add a song link
+// @Description  Adds a link to a song. The platform is detected automatically and the URL is stored in its canonical form. Links to IP addresses, local or private-network domains and non-default ports are refused.
+// @Tags         Links
+// @Accept       json
+// @Produce      json
+// @Param        id    path    int                        true  "Song ID"
+// @Param        link  body    models.NewSongLinkRequest  true  "Link to add"
+// @Param        Idempotency-Key  header  string  false  "Key under which retries of the request get the response to its first use"
+// @Success      200   {object}  models.SongLink  "Added link"
+// @Failure      400   {object}  ProblemDetails "Invalid ID format or malformed link"
+// @Failure      409   {object}  ProblemDetails "Link already exists or Idempotency-Key in use"
+// @Failure      404   {object}  ProblemDetails "Song not found"
+// @Failure      422   {object}  ProblemDetails "Idempotency-Key used before for a different request"
+// @Failure      429   {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500   {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/{id}/links [post]
+func (h *Handler) AddSongLink(c *gin.Context) {
+	ip := c.ClientIP()
+	idParam := c.Param("id")
+
+	logger.Info.Printf("[handlers.AddSongLink] Client IP: %s - Request to add link to song by id: %s", ip, idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.AddSongLink] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	var request models.NewSongLinkRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.AddSongLink] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	link, err := h.songs.AddSongLink(c.Request.Context(), uint(id), request.URL)
+	if err != nil {
+		logger.Error.Printf("[handlers.AddSongLink] Error adding link: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// DeleteSongLink godoc
+// @Summary      Delete a song link
+// @Description  Removes a link from a song. If it was the primary link of the song, the primary link is cleared.
+// @Tags         Links
+// @Accept       json
+// @Produce      json
+// @Param        id      path    int     true  "Song ID"
+// @Param        linkId  path    int     true  "Link ID"
+// @Success      200  {object}  DefaultResponse  "Link successfully deleted"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song or link not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/{id}/links/{linkId} [delete]
+func (h *Handler) DeleteSongLink(c *gin.Context) {
+	ip := c.ClientIP()
+	idParam := c.Param("id")
+	linkIDParam := c.Param("linkId")
+
+	logger.Info.Printf("[handlers.DeleteSongLink] Client IP: %s - Request to delete link %s of song by id: %s", ip, linkIDParam, idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.DeleteSongLink] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	linkID, err := strconv.ParseUint(linkIDParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.DeleteSongLink] Invalid link ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	if err := h.songs.DeleteSongLink(c.Request.Context(), uint(id), uint(linkID)); err != nil {
+		logger.Error.Printf("[handlers.DeleteSongLink] Error deleting link: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.link_deleted")))
+}
+
+// GetBrokenLinks godoc
+// @Summary      Get broken links
+// @Description  Retrieves song links that failed the configured number of health checks in a row, most failing first. last_error says why the last check failed, such as "timed out" or "address not allowed" for links to loopback, private or link-local addresses, which are never requested.
+// @Tags         Links
+// @Accept       json
+// @Produce      json
+// @Param        page   query   int     false "Page number"  default(1)
+// @Param        limit  query   int     false "Number of results per page"  default(10)
+// @Success      200    {array}   models.BrokenLink  "Broken links"
+// @Failure      400    {object}  ProblemDetails   "Invalid pagination parameters"
+// @Failure      429    {object}  ProblemDetails   "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails   "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/broken-links [get]
+func (h *Handler) GetBrokenLinks(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.GetBrokenLinks]: Client with IP=%s, requested to get broken links", ip)
+
+	pageParam := c.Query("page")
+	limitParam := c.Query("limit")
+
+	page := 1
+	limit := 10
+
+	if pageParam != "" {
+		var err error
+		page, err = strconv.Atoi(pageParam)
+		if err != nil {
+			handleError(c, utils.ErrInvalidPaginationParams)
+			return
+		}
+	}
+
+	if limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			handleError(c, utils.ErrInvalidPaginationParams)
+			return
+		}
+	}
+
+	links, err := h.songs.GetBrokenLinks(c.Request.Context(), page, limit)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetBrokenLinks]: Error: %v", err)
+		handleError(c, err)
+		return
+	}
+
+	logger.Info.Printf("[handlers.GetBrokenLinks]: Client with IP=%s, successfully retrieved broken links", ip)
+	c.JSON(http.StatusOK, links)
+}
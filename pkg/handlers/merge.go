@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+)
+
+// GetDuplicateSongs godoc
+// @Summary      Get duplicate song candidates
+// @Description  Lists clusters of songs of the same group that are likely duplicates: their titles match once annotations like "(Remastered)" are dropped, or their lyrics are nearly identical.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        min_similarity  query   number  false  "Minimum lyrics similarity (0-1) of a cluster"  default(0)
+// @Param        page            query   int     false  "Page number"  default(1)
+// @Param        limit           query   int     false  "Number of results per page"  default(10)
+// @Success      200  {array}   models.DuplicateCluster  "Duplicate candidates"
+// @Failure      400  {object}  ProblemDetails "Invalid request parameters"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/duplicates [get]
+func (h *Handler) GetDuplicateSongs(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.GetDuplicateSongs] Client IP: %s - Request to get duplicate songs", ip)
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleError(c, utils.ErrInvalidPaginationParams)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleError(c, utils.ErrInvalidPaginationParams)
+		return
+	}
+	minSimilarity, err := strconv.ParseFloat(c.DefaultQuery("min_similarity", "0"), 64)
+	if err != nil {
+		handleError(c, utils.ErrInvalidRequestParameter)
+		return
+	}
+
+	clusters, err := h.songs.GetDuplicateSongs(c.Request.Context(), minSimilarity, page, limit)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetDuplicateSongs] Error getting duplicate songs: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
+
+// MergeSongs godoc
+// @Summary      Merge songs
+// @Description  Merges the source songs into the target song. "fields" maps a field (group, song, release_date, text, link) to the ID of the song it is taken from; fields not listed keep the target's value. The sources' links move to the target, the sources are soft deleted and their IDs redirect to the target.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        merge  body    models.MergeSongsRequest  true  "Songs to merge"
+// @Param        Idempotency-Key  header  string  false  "Key under which retries of the request get the response to its first use"
+// @Success      200    {object}  models.Song  "The merged song"
+// @Failure      400    {object}  ProblemDetails "Invalid merge request"
+// @Failure      409    {object}  ProblemDetails "Merged song already exists or Idempotency-Key in use"
+// @Failure      404    {object}  ProblemDetails "Song not found"
+// @Failure      422    {object}  ProblemDetails "Idempotency-Key used before for a different request"
+// @Failure      429    {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/merge [post]
+func (h *Handler) MergeSongs(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.MergeSongs] Client IP: %s - Request to merge songs", ip)
+
+	var request models.MergeSongsRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.MergeSongs] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	h.auditTarget(c, request.TargetID)
+	song, err := h.songs.MergeSongs(c.Request.Context(), request)
+	if err != nil {
+		logger.Error.Printf("[handlers.MergeSongs] Error merging songs: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	logger.Info.Printf("[handlers.MergeSongs] Client IP: %s - Merged songs %v into %d", ip, request.SourceIDs, request.TargetID)
+	c.JSON(http.StatusOK, song)
+}
+
+// GetSongMerges godoc
+// @Summary      Get song merge history
+// @Description  Retrieves the merges that folded other songs into this song.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        id   path    int     true  "Song ID"
+// @Success      200  {array}   models.SongMerge  "Merges into the song"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/{id}/merges [get]
+func (h *Handler) GetSongMerges(c *gin.Context) {
+	ip := c.ClientIP()
+	idParam := c.Param("id")
+
+	logger.Info.Printf("[handlers.GetSongMerges] Client IP: %s - Request to get merges of song by id: %s", ip, idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongMerges] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	merges, err := h.songs.GetSongMerges(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongMerges] Error getting merges: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merges)
+}
@@ -0,0 +1,525 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"regexp"
+	"song-library/configs"
+	"song-library/i18n"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestIDKey is where requestID keeps the ID of the request on the gin context.
+const requestIDKey = "requestID"
+
+// requestIDPattern is what an X-Request-ID sent by the client may look like to be kept.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestID gives every request an ID, taken from its X-Request-ID header if it has a
+// usable one, and sends it back in X-Request-ID. Error responses name it as their instance.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set(requestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// languageKey and cataloguesKey are where localise keeps the language negotiated for the
+// request and the catalogues its texts come from.
+const (
+	languageKey   = "language"
+	cataloguesKey = "catalogues"
+)
+
+// localise picks the catalogue that fits the Accept-Language header of the request, which
+// error and message texts are then taken from (see text).
+func localise(catalogues *i18n.Catalogues) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := catalogues.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(languageKey, lang)
+		c.Set(cataloguesKey, catalogues)
+		c.Header("Content-Language", lang)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// requestTimeout puts the deadline configured for the endpoint in timeout_params on the
+// request context, so database queries and provider calls stop once it has passed.
+func requestTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := configs.AppSettings.TimeoutParams
+		timeoutMs := params.DefaultMs
+		if endpointMs, ok := params.Endpoints[c.Request.Method+" "+c.FullPath()]; ok {
+			timeoutMs = endpointMs
+		}
+		if timeoutMs <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requireScope lets a request through only if its caller has scope, and then picks the
+// library the request works on (see resolveLibrary). Callers authenticate with an API key,
+// sent as "X-API-Key: <key>" or "Authorization: Bearer <key>", or with a user access token
+// sent as "Authorization: Bearer <token>"; the role of a user decides its scopes. With
+// auth_params.enabled off every request passes.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var principal *models.Principal
+		if configs.AppSettings.AuthParams.Enabled {
+			var err error
+			principal, err = h.authenticate(c)
+			if err != nil {
+				logger.Info.Printf("[handlers.requireScope] Client IP: %s - Rejected request to %s: %v", c.ClientIP(), c.FullPath(), err)
+				if errors.Is(err, utils.ErrUnauthorized) {
+					c.Header("WWW-Authenticate", `Bearer realm="song-library"`)
+				}
+				handleError(c, err)
+				c.Abort()
+				return
+			}
+			if !principal.HasScope(scope) {
+				logger.Info.Printf("[handlers.requireScope] %s lacks scope %s for %s", principal.Name, scope, c.FullPath())
+				handleError(c, utils.ErrForbidden)
+				c.Abort()
+				return
+			}
+			c.Request = c.Request.WithContext(models.ContextWithPrincipal(c.Request.Context(), principal))
+		}
+
+		if err := h.resolveLibrary(c, principal); err != nil {
+			logger.Info.Printf("[handlers.requireScope] Client IP: %s - Rejected library for %s: %v", c.ClientIP(), c.FullPath(), err)
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// resolveLibrary limits the song queries of the request to one library: the one named by
+// the X-Library header, else the one the caller is limited to, else the default library.
+// A caller limited to a library cannot name another. GET requests with all_libraries=true
+// see every library; that takes the admin scope.
+func (h *Handler) resolveLibrary(c *gin.Context, principal *models.Principal) error {
+	var bound uint
+	if principal != nil {
+		bound = principal.LibraryID
+	}
+
+	allLibraries, err := strconv.ParseBool(c.DefaultQuery("all_libraries", "false"))
+	if err != nil {
+		return utils.ErrInvalidRequestParameter
+	}
+	if allLibraries {
+		if c.Request.Method != http.MethodGet || !hasScope(c, models.ScopeAdmin) {
+			return utils.ErrForbidden
+		}
+		return nil
+	}
+
+	libraryID := bound
+	if slug := strings.TrimSpace(c.GetHeader("X-Library")); slug != "" {
+		library, err := h.libraries.GetLibraryBySlug(c.Request.Context(), slug)
+		if err != nil {
+			return err
+		}
+		if bound != 0 && library.ID != bound {
+			return utils.ErrForbidden
+		}
+		libraryID = library.ID
+	}
+	if libraryID == 0 {
+		libraryID = models.DefaultLibraryID
+	}
+
+	c.Request = c.Request.WithContext(models.ContextWithLibrary(c.Request.Context(), libraryID))
+	return nil
+}
+
+// rateLimit takes the request from its client's budget for the route class and turns it
+// down with 429 once the budget is spent. It runs after requireScope, so clients that
+// authenticated are told apart by key or user rather than by IP address.
+func (h *Handler) rateLimit(class string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !configs.AppSettings.RateLimitParams.Enabled {
+			c.Next()
+			return
+		}
+
+		client := rateLimitClient(c)
+		decision, err := h.limiter.Allow(c.Request.Context(), class, client)
+		if err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		if decision == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		if decision.Err != nil {
+			logger.Info.Printf("[handlers.rateLimit] %s over its %s budget for %s: %v", client, class, c.FullPath(), decision.Err)
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			handleError(c, decision.Err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient names the client a request is counted against: the API key or user it
+// authenticated as, or else its IP address.
+func rateLimitClient(c *gin.Context) string {
+	if principal := models.PrincipalFromContext(c.Request.Context()); principal != nil {
+		if principal.APIKeyID != 0 {
+			return "key:" + strconv.FormatUint(uint64(principal.APIKeyID), 10)
+		}
+		return "user:" + strconv.FormatUint(uint64(principal.UserID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// idempotencyKeyPattern is what an Idempotency-Key header must look like.
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// idempotencyWriteTimeout bounds storing the response to an Idempotency-Key, which happens
+// even when the request itself ran out of time.
+const idempotencyWriteTimeout = 5 * time.Second
+
+// replayedHeaders are the response headers kept with the response to an Idempotency-Key.
+var replayedHeaders = []string{"Content-Type", "Content-Language"}
+
+// idempotent answers a request carrying an Idempotency-Key the client used before with the
+// response to its first use, marked by Idempotent-Replayed: true, without handling it
+// again. Responses with a 5xx status are not kept, so the retry of a request that failed
+// on the server runs again.
+func (h *Handler) idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || configs.AppSettings.IdempotencyParams.WindowHours <= 0 {
+			c.Next()
+			return
+		}
+		if !idempotencyKeyPattern.MatchString(key) {
+			handleError(c, utils.ErrInvalidRequestParameter)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			handleError(c, utils.ErrFailedToParseJSON)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		client := rateLimitClient(c)
+		stored, err := h.idempotency.Begin(ctx, client, key, requestFingerprint(c, body))
+		if err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			logger.Info.Printf("[handlers.idempotent] Replaying the response to idempotency key %q of %s", key, client)
+			for name, value := range stored.Headers {
+				c.Header(name, value)
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.Status, stored.Headers["Content-Type"], stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyWriteTimeout)
+		defer cancel()
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = h.idempotency.Release(ctx, client, key)
+		} else {
+			record := &models.IdempotencyRecord{
+				Client:  client,
+				Key:     key,
+				Status:  recorder.Status(),
+				Headers: make(map[string]string),
+				Body:    recorder.body.Bytes(),
+			}
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					record.Headers[name] = value
+				}
+			}
+			err = h.idempotency.Complete(ctx, record)
+		}
+		if err != nil {
+			logger.Error.Printf("[handlers.idempotent] Failed to settle idempotency key %q of %s: %v", key, client, err)
+		}
+	}
+}
+
+// requestFingerprint tells requests reusing an Idempotency-Key apart: it covers the
+// method, path, library and body of the request.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	library := models.LibraryFromContext(c.Request.Context())
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + " " + strconv.FormatUint(uint64(library), 10) + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// auditSongIDKey and auditBeforeKey are where handlers put the song a request created or
+// targeted when the route does not name it, so recordAudit can capture it.
+const (
+	auditSongIDKey = "auditSongID"
+	auditBeforeKey = "auditBefore"
+)
+
+// auditWriteTimeout bounds writing an audit entry, which happens even when the request
+// itself ran out of time.
+const auditWriteTimeout = 5 * time.Second
+
+// recordAudit adds the request to the audit log once it has been handled, whatever the
+// outcome. It runs after requireScope, so the caller and library are known. On song
+// routes, whose :id is a song ID, the song is captured before and after the request.
+//
+// The response is held back until the entry is stored. If it cannot be, the client gets
+// ErrDatabaseConnectionFailed instead of the response, so no request reports success
+// without its audit entry, although any change it made is kept.
+func (h *Handler) recordAudit(songRoute bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		entry := &models.AuditEntry{
+			Actor:     models.ActorFromContext(ctx),
+			ClientIP:  c.ClientIP(),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			LibraryID: models.LibraryFromContext(ctx),
+		}
+		var songID uint
+		if songRoute {
+			if id, err := strconv.ParseUint(c.Param("id"), 10, 0); err == nil {
+				songID = uint(id)
+				entry.Before = h.songSnapshot(ctx, songID)
+			}
+		}
+
+		writer := &heldResponseWriter{ResponseWriter: c.Writer, header: c.Writer.Header().Clone()}
+		func() {
+			// Restored even if a handler panics, so the recovery middleware can answer.
+			c.Writer = writer
+			defer func() { c.Writer = writer.ResponseWriter }()
+			c.Next()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), auditWriteTimeout)
+		defer cancel()
+		if id, ok := c.Get(auditSongIDKey); ok {
+			songID = id.(uint)
+		}
+		if before, ok := c.Get(auditBeforeKey); ok {
+			entry.Before = before.(*models.Song)
+		}
+		if songID != 0 {
+			entry.SongID = &songID
+			entry.After = h.songSnapshot(ctx, songID)
+		}
+		entry.Status = writer.Status()
+		if err := c.Errors.Last(); err != nil {
+			entry.Error = err.Error()
+		}
+		if err := h.audit.Record(ctx, entry); err != nil {
+			logger.Error.Printf("[handlers.recordAudit] Failed to audit %s %s by %q, which was answered %d: %v", entry.Method, entry.Path, entry.Actor, entry.Status, err)
+			handleError(c, err)
+			return
+		}
+		writer.release()
+	}
+}
+
+// heldResponseWriter keeps the status, headers and body of a response until release
+// writes them out.
+type heldResponseWriter struct {
+	gin.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *heldResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *heldResponseWriter) WriteHeader(status int) {
+	if status > 0 && w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *heldResponseWriter) WriteHeaderNow() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+}
+
+func (w *heldResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	return w.body.Write(data)
+}
+
+func (w *heldResponseWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	return w.body.WriteString(s)
+}
+
+func (w *heldResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *heldResponseWriter) Size() int {
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *heldResponseWriter) Written() bool {
+	return w.status != 0
+}
+
+// Flush is a no-op: nothing may reach the client before release.
+func (w *heldResponseWriter) Flush() {}
+
+// release writes the held response to the writer underneath.
+func (w *heldResponseWriter) release() {
+	header := w.ResponseWriter.Header()
+	for name := range header {
+		delete(header, name)
+	}
+	for name, values := range w.header {
+		header[name] = values
+	}
+	w.ResponseWriter.WriteHeader(w.Status())
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// auditTarget tells recordAudit which existing song the request works on, capturing the
+// song as it is before the change.
+func (h *Handler) auditTarget(c *gin.Context, songID uint) {
+	c.Set(auditSongIDKey, songID)
+	c.Set(auditBeforeKey, h.songSnapshot(c.Request.Context(), songID))
+}
+
+// songSnapshot returns the song, soft deleted or not, or nil if there is none.
+func (h *Handler) songSnapshot(ctx context.Context, id uint) *models.Song {
+	song, err := h.songs.GetSongIncludingDeleted(ctx, id)
+	if err != nil {
+		if !errors.Is(err, utils.ErrSongNotFound) {
+			logger.Error.Printf("[handlers.songSnapshot] Failed to read song %d for the audit log: %v", id, err)
+		}
+		return nil
+	}
+	return song
+}
+
+func (h *Handler) authenticate(c *gin.Context) (*models.Principal, error) {
+	credential := requestCredential(c)
+	// Access tokens are JWTs, three dot-separated parts; API keys contain no dots.
+	if h.users != nil && strings.Count(credential, ".") == 2 {
+		return h.users.Authenticate(credential)
+	}
+
+	key, err := h.apiKeys.Authenticate(c.Request.Context(), credential)
+	if err != nil {
+		return nil, err
+	}
+	return key.Principal(), nil
+}
+
+// hasScope reports whether the caller of the request has scope. With authentication off
+// every caller has every scope.
+func hasScope(c *gin.Context, scope string) bool {
+	if !configs.AppSettings.AuthParams.Enabled {
+		return true
+	}
+	principal := models.PrincipalFromContext(c.Request.Context())
+	return principal != nil && principal.HasScope(scope)
+}
+
+func requestCredential(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/utils"
+)
+
+// problemContentType is the media type of error responses (RFC 7807).
+const problemContentType = "application/problem+json"
+
+// ProblemDetails is the body of every error response. Code is the stable name of the
+// error, as listed by GET /errors, and Instance is the ID of the request, also sent in the
+// X-Request-ID header.
+type ProblemDetails struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail,omitempty"`
+	Instance string             `json:"instance,omitempty"`
+	Code     string             `json:"code"`
+	Errors   []utils.FieldError `json:"errors,omitempty"`
+}
+
+// ErrorDescription is an entry of the error catalogue.
+type ErrorDescription struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// problem is an entry of the error catalogue. Its title and description are the texts
+// <code>.title and <code>.detail of the message catalogues.
+type problem struct {
+	err    error
+	status int
+}
+
+// unexpectedProblem answers errors that match no other entry.
+var unexpectedProblem = problem{utils.ErrUnexpectedError, http.StatusInternalServerError}
+
+// problems maps every sentinel error to its response. handleError takes the first entry
+// the error matches, so wrapped errors are answered like the sentinel they wrap.
+var problems = []problem{
+	{utils.ErrInvalidSongData, http.StatusBadRequest},
+	{utils.ErrInvalidGroup, http.StatusBadRequest},
+	{utils.ErrInvalidSongTitle, http.StatusBadRequest},
+	{utils.ErrInvalidReleaseDate, http.StatusBadRequest},
+	{utils.ErrInvalidText, http.StatusBadRequest},
+	{utils.ErrInvalidLink, http.StatusBadRequest},
+	{utils.ErrInvalidLanguage, http.StatusBadRequest},
+	{utils.ErrInvalidPaginationParams, http.StatusBadRequest},
+	{utils.ErrFailedToParseJSON, http.StatusBadRequest},
+	{utils.ErrInvalidID, http.StatusBadRequest},
+	{utils.ErrInvalidRequestBody, http.StatusBadRequest},
+	{utils.ErrInvalidRequestParameter, http.StatusBadRequest},
+	{utils.ErrMissingRequiredField, http.StatusBadRequest},
+	{utils.ErrInvalidMergeRequest, http.StatusBadRequest},
+	{utils.ErrInvalidAPIKeyRequest, http.StatusBadRequest},
+	{utils.ErrInvalidUserRequest, http.StatusBadRequest},
+	{utils.ErrInvalidLibraryRequest, http.StatusBadRequest},
+
+	{utils.ErrUnauthorized, http.StatusUnauthorized},
+	{utils.ErrInvalidCredentials, http.StatusUnauthorized},
+	{utils.ErrForbidden, http.StatusForbidden},
+
+	{utils.ErrSongNotFound, http.StatusNotFound},
+	{utils.ErrSongNotFoundInDatabase, http.StatusNotFound},
+	{utils.ErrGroupNotFound, http.StatusNotFound},
+	{utils.ErrLinkNotFound, http.StatusNotFound},
+	{utils.ErrAPIKeyNotFound, http.StatusNotFound},
+	{utils.ErrUserNotFound, http.StatusNotFound},
+	{utils.ErrLibraryNotFound, http.StatusNotFound},
+
+	{utils.ErrSongAlreadyExists, http.StatusConflict},
+	{utils.ErrLinkAlreadyExists, http.StatusConflict},
+	{utils.ErrUserAlreadyExists, http.StatusConflict},
+	{utils.ErrLibraryAlreadyExists, http.StatusConflict},
+	{utils.ErrIdempotencyKeyInUse, http.StatusConflict},
+
+	{utils.ErrBatchTooLarge, http.StatusRequestEntityTooLarge},
+	{utils.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+	{utils.ErrBatchRolledBack, http.StatusFailedDependency},
+
+	{utils.ErrRateLimitExceeded, http.StatusTooManyRequests},
+	{utils.ErrQuotaExceeded, http.StatusTooManyRequests},
+
+	{utils.ErrSongDeleteFailed, http.StatusInternalServerError},
+	{utils.ErrSongUpdateFailed, http.StatusInternalServerError},
+	{utils.ErrFailedToGenerateSwagger, http.StatusInternalServerError},
+	{utils.ErrFailedToFetchSongInfoFromAPI, http.StatusBadGateway},
+	{utils.ErrAPIRequestFailed, http.StatusBadGateway},
+	{utils.ErrInvalidResponse, http.StatusBadGateway},
+	{utils.ErrDatabaseConnectionFailed, http.StatusServiceUnavailable},
+	{utils.ErrRequestTimeout, http.StatusGatewayTimeout},
+}
+
+// GetErrorCatalogue godoc
+// @Summary      List error codes
+// @Description  Lists every error code the API answers with, its HTTP status and what it means, in the language negotiated from Accept-Language. Error responses are application/problem+json documents whose code field is one of these.
+// @Tags         Errors
+// @Produce      json
+// @Param        Accept-Language  header  string  false  "Preferred languages, such as ru-RU,ru;q=0.9"
+// @Success      200  {array}  ErrorDescription  "Error catalogue"
+// @Router       /errors [get]
+func GetErrorCatalogue(c *gin.Context) {
+	entries := make([]problem, 0, len(problems)+1)
+	entries = append(append(entries, problems...), unexpectedProblem)
+	catalogue := make([]ErrorDescription, 0, len(entries))
+	for _, p := range entries {
+		code := p.err.Error()
+		catalogue = append(catalogue, ErrorDescription{
+			Code:        code,
+			Status:      p.status,
+			Title:       text(c, code+".title"),
+			Description: text(c, code+".detail"),
+		})
+	}
+	c.JSON(http.StatusOK, catalogue)
+}
+
+// lookupProblem returns the catalogue entry err matches, or that of ErrUnexpectedError.
+func lookupProblem(err error) (problem, bool) {
+	for _, p := range problems {
+		if errors.Is(err, p.err) {
+			return p, true
+		}
+	}
+	return unexpectedProblem, false
+}
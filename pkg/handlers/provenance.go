@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/utils"
+	"strconv"
+)
+
+// GetSongProvenance godoc
+// @Summary      Get song field provenance
+// @Description  Retrieves where each enriched field of a song came from: the source, when it was recorded and the provider response hash.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        id   path    int     true  "Song ID"
+// @Success      200  {array}   models.SongFieldProvenance  "Provenance of the song fields"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /songs/{id}/provenance [get]
+func (h *Handler) GetSongProvenance(c *gin.Context) {
+	ip := c.ClientIP()
+	idParam := c.Param("id")
+
+	logger.Info.Printf("[handlers.GetSongProvenance] Client IP: %s - Request to get provenance of song by id: %s", ip, idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongProvenance] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	records, err := h.songs.GetSongProvenance(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Error.Printf("[handlers.GetSongProvenance] Error getting provenance: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,54 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/utils"
+	"testing"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+	// One token a second in a bucket of two.
+	configs.AppSettings.RateLimitParams = models.RateLimitParams{
+		Enabled: true,
+		Classes: map[string]models.RateLimitClass{models.RateClassRead: {RequestsPerMinute: 60, Burst: 2}},
+	}
+
+	for _, remaining := range []string{"1", "0"} {
+		response := serve(t, router, http.MethodGet, "/songs/", writerKey, "")
+		if response.Code != http.StatusOK {
+			t.Fatalf("GET /songs/ = %d %s", response.Code, response.Body)
+		}
+		if got := response.Header(); got.Get("RateLimit-Limit") != "2" || got.Get("RateLimit-Remaining") != remaining || got.Get("RateLimit-Reset") == "" || got.Get("Retry-After") != "" {
+			t.Errorf("headers = %v, want a limit of 2 with %s remaining and no Retry-After", got, remaining)
+		}
+	}
+
+	response := serve(t, router, http.MethodGet, "/songs/", writerKey, "")
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusTooManyRequests || problem.Code != utils.ErrRateLimitExceeded.Error() {
+		t.Fatalf("GET /songs/ over the limit = %d %s, want 429 %s", response.Code, response.Body, utils.ErrRateLimitExceeded)
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "2",
+		"Retry-After":         "1",
+		"Content-Type":        "application/problem+json",
+	} {
+		if got := response.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	// Each key has a budget of its own.
+	if response := serve(t, router, http.MethodGet, "/songs/", adminKey, ""); response.Code != http.StatusOK {
+		t.Errorf("GET /songs/ with another key = %d %s, want 200", response.Code, response.Body)
+	}
+}
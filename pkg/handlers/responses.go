@@ -10,16 +10,30 @@ func NewDefaultResponse(message string) DefaultResponse {
 	}
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+type LyricsResponse struct {
+	Lyrics []string `json:"lyrics"`
 }
 
-func NewErrorResponse(message string) ErrorResponse {
-	return ErrorResponse{
-		Error: message,
-	}
+type BulkResponse struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
 }
 
-type LyricsResponse struct {
-	Lyrics []string `json:"lyrics"`
+// SongBatchResponse lists the outcome of each operation of a song batch, in the order they
+// were sent.
+type SongBatchResponse struct {
+	Atomic    bool              `json:"atomic"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []SongBatchResult `json:"results"`
+}
+
+// SongBatchResult is the outcome of one operation: the status it would have been answered
+// with on its own, and the code and title of its error if it failed.
+type SongBatchResult struct {
+	Op     string `json:"op"`
+	ID     uint   `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
 }
@@ -7,32 +7,135 @@ import (
 	"net/http"
 	"song-library/configs"
 	_ "song-library/docs"
+	"song-library/i18n"
+	"song-library/models"
+	services "song-library/pkg/services"
 )
 
-func InitRoutes() *gin.Engine {
+type Handler struct {
+	songs       *services.SongService
+	details     *services.SongDetailService
+	libraries   *services.LibraryService
+	apiKeys     *services.APIKeyService
+	users       *services.UserService
+	limiter     *services.RateLimiter
+	audit       *services.AuditService
+	idempotency *services.IdempotencyService
+	locales     *i18n.Catalogues
+}
+
+// NewHandler builds the handler; users may be nil when authentication is off, which leaves
+// out the account routes.
+func NewHandler(songs *services.SongService, details *services.SongDetailService, libraries *services.LibraryService, apiKeys *services.APIKeyService, users *services.UserService, limiter *services.RateLimiter, audit *services.AuditService, idempotency *services.IdempotencyService, locales *i18n.Catalogues) *Handler {
+	return &Handler{
+		songs:       songs,
+		details:     details,
+		libraries:   libraries,
+		apiKeys:     apiKeys,
+		users:       users,
+		limiter:     limiter,
+		audit:       audit,
+		idempotency: idempotency,
+		locales:     locales,
+	}
+}
+
+func (h *Handler) InitRoutes() *gin.Engine {
 	r := gin.Default()
 	gin.SetMode(configs.AppSettings.AppParams.GinMode)
+	r.Use(requestID(), localise(h.locales), requestTimeout())
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.GET("/ping", PingPong)
+	r.GET("/errors", GetErrorCatalogue)
+
+	read := h.requireScope(models.ScopeSongsRead)
+	write := h.requireScope(models.ScopeSongsWrite)
+	purge := h.requireScope(models.ScopeSongsPurge)
+	admin := h.requireScope(models.ScopeAdmin)
+
+	reads := h.rateLimit(models.RateClassRead)
+	writes := h.rateLimit(models.RateClassWrite)
+	enrichment := h.rateLimit(models.RateClassEnrichment)
+
+	audited := h.recordAudit(false)
+	auditedSong := h.recordAudit(true)
+	// API keys and tokens are left out: their responses hold secrets that must not be kept.
+	idempotent := h.idempotent()
 
 	songGroup := r.Group("/songs")
 	{
-		songGroup.GET("/", GetSongs)
-		songGroup.GET("/:id", GetSongByID)
-		songGroup.PUT("/:id", UpdateSong)
-		songGroup.POST("/", AddSong)
-		songGroup.DELETE("/:id", SoftDeleteSong)
-		songGroup.DELETE("/hard/:id", HardDeleteSong)
+		songGroup.GET("/", read, reads, h.GetSongs)
+		songGroup.GET("/broken-links", read, reads, h.GetBrokenLinks)
+		songGroup.GET("/duplicates", read, reads, h.GetDuplicateSongs)
+		songGroup.POST("/merge", write, writes, idempotent, auditedSong, h.MergeSongs)
+		songGroup.POST("/batch", write, writes, idempotent, audited, h.ApplySongBatch)
+		songGroup.GET("/:id", read, reads, h.GetSongByID)
+		songGroup.GET("/:id/provenance", read, reads, h.GetSongProvenance)
+		songGroup.GET("/:id/merges", read, reads, h.GetSongMerges)
+		songGroup.GET("/:id/links", read, reads, h.GetSongLinks)
+		songGroup.POST("/:id/links", write, writes, idempotent, auditedSong, h.AddSongLink)
+		songGroup.DELETE("/:id/links/:linkId", write, writes, auditedSong, h.DeleteSongLink)
+		songGroup.PUT("/:id", write, writes, auditedSong, h.UpdateSong)
+		songGroup.POST("/", write, enrichment, idempotent, auditedSong, h.AddSong)
+		songGroup.DELETE("/:id", write, writes, auditedSong, h.SoftDeleteSong)
+		songGroup.DELETE("/hard/:id", purge, writes, auditedSong, h.HardDeleteSong)
+	}
+
+	lyricsGroup := r.Group("/lyrics", read, reads)
+	{
+		lyricsGroup.GET("/:title", h.GetLyrics)
+		lyricsGroup.GET("/", h.GetLyricsByText)
 	}
 
-	lyricsGroup := r.Group("/lyrics")
+	// Song details are shared by all libraries, so only admins may change them.
+	infoGroup := r.Group("/API/info")
 	{
-		lyricsGroup.GET("/:title", GetLyrics)
-		lyricsGroup.GET("/", GetLyricsByText)
+		infoGroup.GET("", read, reads, h.ApiInfo)
+		infoGroup.POST("", admin, writes, idempotent, audited, h.AddSongDetail)
+		infoGroup.PUT("", admin, writes, audited, h.UpdateSongDetail)
+		infoGroup.DELETE("", admin, writes, audited, h.DeleteSongDetail)
+		infoGroup.POST("/bulk", admin, writes, idempotent, audited, h.BulkUpsertSongDetails)
+	}
+
+	libraryGroup := r.Group("/libraries", h.requireScope(models.ScopeAdmin))
+	{
+		libraryGroup.GET("", h.GetLibraries)
+		libraryGroup.POST("", idempotent, audited, h.CreateLibrary)
+	}
+
+	apiKeyGroup := r.Group("/api-keys", h.requireScope(models.ScopeAdmin))
+	{
+		apiKeyGroup.GET("", h.GetAPIKeys)
+		apiKeyGroup.POST("", audited, h.CreateAPIKey)
+		apiKeyGroup.POST("/:id/rotate", audited, h.RotateAPIKey)
+		apiKeyGroup.DELETE("/:id", audited, h.RevokeAPIKey)
+	}
+
+	auditGroup := r.Group("/audit", h.requireScope(models.ScopeAdmin))
+	{
+		auditGroup.GET("", h.GetAuditEntries)
+		auditGroup.GET("/verify", h.VerifyAuditChain)
+	}
+
+	if h.users != nil {
+		// Counted per IP address, which also slows down guessing passwords.
+		authGroup := r.Group("/auth", writes, audited)
+		{
+			authGroup.POST("/register", h.Register)
+			authGroup.POST("/login", h.Login)
+			authGroup.POST("/refresh", h.RefreshToken)
+			authGroup.POST("/logout", h.Logout)
+		}
+
+		userGroup := r.Group("/users", h.requireScope(models.ScopeAdmin))
+		{
+			userGroup.GET("", h.GetUsers)
+			userGroup.POST("", idempotent, audited, h.CreateUser)
+			userGroup.PUT("/:id/role", audited, h.UpdateUserRole)
+		}
 	}
 
-	r.GET("API/info", ApiInfo)
 	return r
 }
 
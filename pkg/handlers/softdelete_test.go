@@ -0,0 +1,192 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"song-library/configs"
+	"song-library/i18n"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/handlers"
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/memory"
+	services "song-library/pkg/services"
+	"strings"
+	"testing"
+)
+
+const (
+	adminKey  = "admin-key-0123456789"
+	writerKey = "writer-key-0123456789"
+)
+
+// newTestServer serves the routes on the memory backend with authentication on, and
+// returns the repositories behind them. adminKey has the admin scope and writerKey may
+// read and change songs. Each of replace may swap repositories for the services.
+func newTestServer(t *testing.T, replace ...func(repos *repository.Repositories)) (*gin.Engine, repository.Repositories) {
+	t.Helper()
+	for _, l := range []**log.Logger{&logger.Info, &logger.Error, &logger.Warning, &logger.Debug} {
+		*l = log.New(io.Discard, "", 0)
+	}
+	gin.DefaultWriter = io.Discard
+	configs.AppSettings = models.AppConfig{
+		AppParams:    models.AppParams{GinMode: gin.TestMode},
+		AuthParams:   models.AuthParams{Enabled: true},
+		LocaleParams: models.LocaleParams{Directory: "../../configs/locales", DefaultLanguage: "en"},
+	}
+
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	apiKeys := services.NewAPIKeyService(repos.APIKeys, repos.Libraries)
+	ctx := context.Background()
+	if err := apiKeys.EnsureAPIKey(ctx, "admin", adminKey, []string{models.ScopeAdmin}); err != nil {
+		t.Fatalf("EnsureAPIKey(admin): %v", err)
+	}
+	if err := apiKeys.EnsureAPIKey(ctx, "writer", writerKey, []string{models.ScopeSongsRead, models.ScopeSongsWrite}); err != nil {
+		t.Fatalf("EnsureAPIKey(writer): %v", err)
+	}
+	locales, err := i18n.Load(configs.AppSettings.LocaleParams)
+	if err != nil {
+		t.Fatalf("i18n.Load: %v", err)
+	}
+
+	used := repos
+	for _, r := range replace {
+		r(&used)
+	}
+	handler := handlers.NewHandler(
+		services.NewSongService(used, memory.NewUnitOfWork(store)),
+		services.NewSongDetailService(used.SongDetails),
+		services.NewLibraryService(used.Libraries),
+		apiKeys,
+		nil,
+		services.NewRateLimiter(used.Quotas),
+		services.NewAuditService(used.Audit),
+		services.NewIdempotencyService(used.Idempotency),
+		locales,
+	)
+	return handler.InitRoutes(), repos
+}
+
+func serve(t *testing.T, router *gin.Engine, method, target, key, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("X-API-Key", key)
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestSoftDeletedSongIsHidden(t *testing.T) {
+	router, repos := newTestServer(t)
+	ctx := context.Background()
+	deleted := &models.Song{Group: "Muse", Song: "Uprising", Text: "They will not force us\n\nThey will stop degrading us"}
+	live := &models.Song{Group: "Muse", Song: "Uprising (Live)", Text: "They will not force us\n\nThey will stop degrading us"}
+	for _, song := range []*models.Song{deleted, live} {
+		if err := repos.Songs.AddSong(ctx, song); err != nil {
+			t.Fatalf("AddSong(%q): %v", song.Song, err)
+		}
+	}
+
+	if response := serve(t, router, http.MethodGet, "/songs/duplicates", writerKey, ""); !strings.Contains(response.Body.String(), `"title":"uprising"`) {
+		t.Fatalf("GET /songs/duplicates before the delete = %d %s, want the two songs as duplicates", response.Code, response.Body)
+	}
+	if response := serve(t, router, http.MethodDelete, "/songs/1", writerKey, ""); response.Code != http.StatusOK {
+		t.Fatalf("DELETE /songs/1 = %d %s", response.Code, response.Body)
+	}
+
+	for _, test := range []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"GetSongByID", "/songs/1", http.StatusNotFound},
+		{"GetLyrics", "/lyrics/Uprising", http.StatusNotFound},
+		{"GetSongs", "/songs/?group=Muse", http.StatusOK},
+		{"GetLyricsByText", "/lyrics/?search=force", http.StatusOK},
+		{"GetDuplicateSongs", "/songs/duplicates", http.StatusOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response := serve(t, router, http.MethodGet, test.target, writerKey, "")
+			if response.Code != test.status {
+				t.Fatalf("GET %s = %d %s, want %d", test.target, response.Code, response.Body, test.status)
+			}
+			if body := response.Body.String(); strings.Contains(body, `"id":1,`) || strings.Contains(body, `"song":"Uprising"`) {
+				t.Errorf("GET %s = %s, want the deleted song left out", test.target, body)
+			}
+		})
+	}
+
+	// The lyrics search finds the live version only, and no duplicates are left.
+	if response := serve(t, router, http.MethodGet, "/lyrics/?search=force", writerKey, ""); !strings.Contains(response.Body.String(), "They will not force us") {
+		t.Errorf("GET /lyrics/?search=force = %s, want the verse of the live version", response.Body)
+	}
+	if response := serve(t, router, http.MethodGet, "/songs/duplicates", writerKey, ""); strings.TrimSpace(response.Body.String()) != "[]" {
+		t.Errorf("GET /songs/duplicates = %s, want no clusters", response.Body)
+	}
+
+	// SongExists no longer sees the song, so its group and title can be used again.
+	batch := `{"operations":[{"op":"create","group":"Muse","song":"Uprising"}]}`
+	response := serve(t, router, http.MethodPost, "/songs/batch", writerKey, batch)
+	var result struct {
+		Results []struct {
+			Status int `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil || len(result.Results) != 1 || result.Results[0].Status != http.StatusCreated {
+		t.Errorf("POST /songs/batch creating the deleted song again = %d %s, want it created", response.Code, response.Body)
+	}
+}
+
+func TestSoftDeletedSongDetailIsHidden(t *testing.T) {
+	router, repos := newTestServer(t)
+	detail := &models.SongDetail{Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009"}
+	if err := repos.SongDetails.AddSongDetail(context.Background(), detail); err != nil {
+		t.Fatalf("AddSongDetail: %v", err)
+	}
+
+	target := "/API/info?group=Muse&song=Uprising"
+	if response := serve(t, router, http.MethodGet, target, writerKey, ""); response.Code != http.StatusOK {
+		t.Fatalf("GET %s before the delete = %d %s", target, response.Code, response.Body)
+	}
+	if response := serve(t, router, http.MethodDelete, target, writerKey, ""); response.Code != http.StatusForbidden {
+		t.Fatalf("DELETE %s without the admin scope = %d %s, want 403", target, response.Code, response.Body)
+	}
+	if response := serve(t, router, http.MethodDelete, target, adminKey, ""); response.Code != http.StatusOK {
+		t.Fatalf("DELETE %s = %d %s", target, response.Code, response.Body)
+	}
+	if response := serve(t, router, http.MethodGet, target, writerKey, ""); response.Code != http.StatusNotFound {
+		t.Errorf("GET %s after the delete = %d %s, want 404", target, response.Code, response.Body)
+	}
+}
+
+func TestIncludeDeletedNeedsAdmin(t *testing.T) {
+	router, repos := newTestServer(t)
+	song := &models.Song{Group: "Muse", Song: "Uprising"}
+	if err := repos.Songs.AddSong(context.Background(), song); err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+	if err := repos.Songs.SoftDeleteSong(context.Background(), song.ID, "tester"); err != nil {
+		t.Fatalf("SoftDeleteSong: %v", err)
+	}
+
+	for _, target := range []string{"/songs/?include_deleted=true", "/songs/1?include_deleted=true"} {
+		t.Run(target, func(t *testing.T) {
+			if response := serve(t, router, http.MethodGet, target, writerKey, ""); response.Code != http.StatusForbidden {
+				t.Errorf("GET %s without the admin scope = %d %s, want 403", target, response.Code, response.Body)
+			}
+			response := serve(t, router, http.MethodGet, target, adminKey, "")
+			if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"song":"Uprising"`) {
+				t.Errorf("GET %s as admin = %d %s, want the deleted song", target, response.Code, response.Body)
+			}
+		})
+	}
+}
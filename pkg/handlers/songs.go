@@ -1,12 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"song-library/logger"
 	"song-library/models"
-	services "song-library/pkg/services"
 	"song-library/utils"
 	"strconv"
 )
@@ -21,11 +21,16 @@ import (
 // @Param        song     query   string  false  "Song name"
 // @Param        page     query   int     false  "Page number"  default(1)
 // @Param        limit    query   int     false  "Number of results per page"  default(10)
+// @Param        include_deleted  query  bool  false  "Include soft-deleted songs (needs the admin scope)"  default(false)
+// @Param        all_libraries    query  bool  false  "List the songs of every library (needs the admin scope)"  default(false)
+// @Param        X-Library        header  string  false  "Slug of the library to list; defaults to the caller's library or the default library"
 // @Success      200      {array}  models.Song   "Success"  "List of songs"
-// @Failure      400      {object}  ErrorResponse  "Invalid request"
-// @Failure      500      {object}  ErrorResponse  "Internal server error"
+// @Failure      400      {object}  ProblemDetails "Invalid request"
+// @Failure      429      {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500      {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs [get]
-func GetSongs(c *gin.Context) {
+func (h *Handler) GetSongs(c *gin.Context) {
 	ip := c.ClientIP()
 	logger.Info.Printf("[GetSongs]: Client with IP=%s, requested to get songs", ip)
 	group := c.Query("group")
@@ -55,7 +60,17 @@ func GetSongs(c *gin.Context) {
 		}
 	}
 
-	songs, err := services.GetSongs(group, song, page, limit)
+	includeDeleted, err := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		handleError(c, utils.ErrInvalidRequestParameter)
+		return
+	}
+	if includeDeleted && !hasScope(c, models.ScopeAdmin) {
+		handleError(c, utils.ErrForbidden)
+		return
+	}
+
+	songs, err := h.songs.GetSongs(c.Request.Context(), group, song, includeDeleted, page, limit)
 	if err != nil {
 		logger.Error.Printf("[handlers.GetSongs]: Error: %v", err)
 		handleError(c, err)
@@ -64,7 +79,7 @@ func GetSongs(c *gin.Context) {
 
 	if songs == nil {
 		logger.Info.Printf("[handlers.GetSongs]: Client with IP=%s, no songs found", ip)
-		c.JSON(http.StatusNotFound, DefaultResponse{Message: "No songs found."})
+		c.JSON(http.StatusNotFound, NewDefaultResponse(text(c, "message.no_songs_found")))
 		return
 	}
 
@@ -79,12 +94,18 @@ func GetSongs(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id   path    int     true  "Song ID"
+// @Param        include_deleted  query  bool  false  "Return the song even if it is soft deleted (needs the admin scope)"  default(false)
+// @Param        all_libraries    query  bool  false  "Look the song up in every library (needs the admin scope)"  default(false)
+// @Param        X-Library        header  string  false  "Slug of the library the song is in; defaults to the caller's library or the default library"
 // @Success      200  {object}  models.Song   "Success"  "Song details"
-// @Failure      400  {object}  ErrorResponse  "Invalid ID format"
-// @Failure      404  {object}  ErrorResponse  "Song not found"
-// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Success      301  "The song was merged; Location points to the surviving song"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs/{id} [get]
-func GetSongByID(c *gin.Context) {
+func (h *Handler) GetSongByID(c *gin.Context) {
 	ip := c.ClientIP()
 	idParam := c.Param("id")
 
@@ -97,7 +118,29 @@ func GetSongByID(c *gin.Context) {
 		return
 	}
 
-	song, err := services.GetSongByID(uint(id))
+	includeDeleted, err := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		handleError(c, utils.ErrInvalidRequestParameter)
+		return
+	}
+	if includeDeleted && !hasScope(c, models.ScopeAdmin) {
+		handleError(c, utils.ErrForbidden)
+		return
+	}
+
+	var song *models.Song
+	if includeDeleted {
+		song, err = h.songs.GetSongIncludingDeleted(c.Request.Context(), uint(id))
+	} else {
+		song, err = h.songs.GetSongByID(c.Request.Context(), uint(id))
+	}
+	if errors.Is(err, utils.ErrSongNotFound) {
+		if targetID, redirectErr := h.songs.ResolveSongRedirect(c.Request.Context(), uint(id)); redirectErr == nil && targetID != 0 {
+			logger.Info.Printf("[handlers.GetSongByID] Song %d was merged into %d", id, targetID)
+			c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/songs/%d", targetID))
+			return
+		}
+	}
 	if err != nil {
 		logger.Error.Printf("[handlers.GetSongByID] Error getting song: %s", err)
 		handleError(c, err)
@@ -119,51 +162,60 @@ func GetSongByID(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        song  body    models.NewSongRequest  true  "New song details"
+// @Param        Idempotency-Key  header  string  false  "Key under which retries of the request get the response to its first use"
 // @Success      200   {object}  DefaultResponse   "Success"  "Song added successfully with additional data."
 // @Success      200   {object}  DefaultResponse   "Success"  "Song added successfully with provided data only."
-// @Failure      400   {object}  ErrorResponse  "Invalid request body"
-// @Failure      500   {object}  ErrorResponse  "Internal server error"
+// @Failure      400   {object}  ProblemDetails "Invalid request body"
+// @Failure      409   {object}  ProblemDetails "Song already exists or Idempotency-Key in use"
+// @Failure      422   {object}  ProblemDetails "Idempotency-Key used before for a different request"
+// @Failure      429   {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500   {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs [post]
-func AddSong(c *gin.Context) {
+func (h *Handler) AddSong(c *gin.Context) {
 	ip := c.ClientIP()
 	logger.Info.Printf("[handlers.AddSong] Client IP: %s - Request to add a new song", ip)
 	var newSongRequest models.NewSongRequest
-	if err := c.ShouldBindJSON(&newSongRequest); err != nil {
+	if err := bindJSON(c, &newSongRequest); err != nil {
 		logger.Error.Printf("[handlers.AddSong] Error binding JSON: %s", err)
-		handleError(c, utils.ErrInvalidRequestBody)
+		handleError(c, err)
 		return
 	}
 
-	song, err := services.AddSong(newSongRequest)
+	song, err := h.songs.AddSong(c.Request.Context(), newSongRequest)
 	if err != nil {
 		logger.Error.Printf("[handlers.AddSong] Error adding song: %s", err)
 		handleError(c, err)
 		return
 	}
+	c.Set(auditSongIDKey, song.ID)
 
 	if song.ReleaseDate != "" || song.Text != "" || song.Link != "" {
-		response := DefaultResponse{Message: "Song added successfully with additional data."}
+		response := NewDefaultResponse(text(c, "message.song_added_with_data"))
 		c.JSON(http.StatusOK, response)
 	} else {
-		response := DefaultResponse{Message: "Song added successfully with provided data only."}
+		response := NewDefaultResponse(text(c, "message.song_added"))
 		c.JSON(http.StatusOK, response)
 	}
 }
 
 // UpdateSong godoc
 // @Summary      Update an existing song
-// @Description  Updates an existing song by its unique ID with new details, such as title, artist, release date, and link.
+// @Description  Updates an existing song by its unique ID with new details, such as title, artist, release date, link and language. Every invalid field is listed in the errors of the response.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
 // @Param        id   path    int     true  "Song ID"
-// @Param        song body    	models.Song  true  "Updated song details"
+// @Param        song body    	models.UpdateSongRequest  true  "Updated song details; fields left out are cleared, unknown fields are rejected"
 // @Success      200  {object}  DefaultResponse   "Success"  "Song updated successfully"
-// @Failure      400  {object}  ErrorResponse  "Invalid ID format or request body"
-// @Failure      404  {object}  ErrorResponse  "Song not found"
-// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format or request body"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      409  {object}  ProblemDetails "Another song has this group and title"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs/{id} [put]
-func UpdateSong(c *gin.Context) {
+func (h *Handler) UpdateSong(c *gin.Context) {
 	ip := c.ClientIP()
 	idParam := c.Param("id")
 
@@ -176,21 +228,21 @@ func UpdateSong(c *gin.Context) {
 		return
 	}
 
-	var songUpdate models.Song
-	if err := c.ShouldBindJSON(&songUpdate); err != nil {
+	var songUpdate models.UpdateSongRequest
+	if err := bindJSON(c, &songUpdate); err != nil {
 		logger.Error.Printf("[handlers.UpdateSong] Error binding JSON: %s", err)
-		handleError(c, utils.ErrInvalidRequestBody)
+		handleError(c, err)
 		return
 	}
 
-	err = services.UpdateSong(uint(id), &songUpdate)
+	err = h.songs.UpdateSong(c.Request.Context(), uint(id), songUpdate)
 	if err != nil {
 		logger.Error.Printf("[handlers.UpdateSong] Error updating song: %s", err)
 		handleError(c, err)
 		return
 	}
 
-	response := DefaultResponse{Message: fmt.Sprintf("Song with id: %d updated successfully.", id)}
+	response := NewDefaultResponse(text(c, "message.song_updated", "{id}", strconv.FormatUint(id, 10)))
 	c.JSON(http.StatusOK, response)
 }
 
@@ -202,11 +254,13 @@ func UpdateSong(c *gin.Context) {
 // @Produce      json
 // @Param        id   path    int     true  "Song ID"
 // @Success      200  {object}  DefaultResponse   "Success"  "Song successfully soft deleted"
-// @Failure      400  {object}  ErrorResponse  "Invalid ID format"
-// @Failure      404  {object}  ErrorResponse  "Song not found"
-// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs/{id} [delete]
-func SoftDeleteSong(c *gin.Context) {
+func (h *Handler) SoftDeleteSong(c *gin.Context) {
 	ip := c.ClientIP()
 	idParam := c.Param("id")
 
@@ -219,14 +273,14 @@ func SoftDeleteSong(c *gin.Context) {
 		return
 	}
 
-	err = services.SoftDeleteSong(uint(id))
+	err = h.songs.SoftDeleteSong(c.Request.Context(), uint(id))
 	if err != nil {
 		logger.Error.Printf("[handlers.SoftDeleteSong] Error soft deleting song: %s", err)
 		handleError(c, err)
 		return
 	}
 
-	response := NewDefaultResponse("Song successfully deleted")
+	response := NewDefaultResponse(text(c, "message.song_deleted"))
 	c.JSON(http.StatusOK, response)
 }
 
@@ -238,11 +292,13 @@ func SoftDeleteSong(c *gin.Context) {
 // @Produce      json
 // @Param        id   path    int     true  "Song ID"
 // @Success      200  {object}  DefaultResponse   "Success"  "Song successfully hard deleted"
-// @Failure      400  {object}  ErrorResponse  "Invalid ID format"
-// @Failure      404  {object}  ErrorResponse  "Song not found"
-// @Failure      500  {object}  ErrorResponse  "Internal server error"
+// @Failure      400  {object}  ProblemDetails "Invalid ID format"
+// @Failure      404  {object}  ProblemDetails "Song not found"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /songs/hard/{id} [delete]
-func HardDeleteSong(c *gin.Context) {
+func (h *Handler) HardDeleteSong(c *gin.Context) {
 	ip := c.ClientIP()
 	idParam := c.Param("id")
 
@@ -255,14 +311,14 @@ func HardDeleteSong(c *gin.Context) {
 		return
 	}
 
-	err = services.HardDeleteSong(uint(id))
+	err = h.songs.HardDeleteSong(c.Request.Context(), uint(id))
 	if err != nil {
 		logger.Error.Printf("[handlers.HardDeleteSong] Error hard deleting song: %s", err)
 		handleError(c, err)
 		return
 	}
 
-	response := NewDefaultResponse("Song successfully hard deleted")
+	response := NewDefaultResponse(text(c, "message.song_hard_deleted"))
 	c.JSON(http.StatusOK, response)
 }
 
@@ -276,11 +332,13 @@ func HardDeleteSong(c *gin.Context) {
 // @Param        page   query   int     false "Page number" (defaults to 1)
 // @Param        limit  query   int     false "Results per page" (defaults to 10)
 // @Success      200    {object}  LyricsResponse   "Lyrics data"
-// @Failure      400    {object}  ErrorResponse    "Invalid pagination parameters"
-// @Failure      404    {object}  ErrorResponse    "Song not found"
-// @Failure      500    {object}  ErrorResponse    "Internal server error"
+// @Failure      400    {object}  ProblemDetails   "Invalid pagination parameters"
+// @Failure      404    {object}  ProblemDetails   "Song not found"
+// @Failure      429    {object}  ProblemDetails   "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails   "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /lyrics/{title} [get]
-func GetLyrics(c *gin.Context) {
+func (h *Handler) GetLyrics(c *gin.Context) {
 	ip := c.ClientIP()
 	logger.Info.Printf("[handlers.GetLyrics]: Client with IP=%s, requested to get lyrics", ip)
 
@@ -310,7 +368,7 @@ func GetLyrics(c *gin.Context) {
 
 	logger.Info.Printf("[handlers.GetLyrics]: Searching for song: %s", song)
 
-	lyrics, err := services.GetLyrics(song, page, limit)
+	lyrics, err := h.songs.GetLyrics(c.Request.Context(), song, page, limit)
 	if err != nil {
 		logger.Error.Printf("[handlers.GetLyrics]: Error: %v", err)
 		handleError(c, err)
@@ -334,11 +392,13 @@ func GetLyrics(c *gin.Context) {
 // @Param        page   query  int     false "Page number" (defaults to 1)
 // @Param        limit  query  int     false "Results per page" (defaults to 10)
 // @Success      200    {object}  LyricsResponse   "Lyrics data"
-// @Failure      400    {object}  ErrorResponse    "Invalid text or pagination parameters"
-// @Failure      404    {object}  ErrorResponse    "No lyrics found"
-// @Failure      500    {object}  ErrorResponse    "Internal server error"
+// @Failure      400    {object}  ProblemDetails   "Invalid text or pagination parameters"
+// @Failure      404    {object}  ProblemDetails   "No lyrics found"
+// @Failure      429    {object}  ProblemDetails   "Rate limit or daily quota exceeded"
+// @Failure      500    {object}  ProblemDetails   "Internal server error"
+// @Security     ApiKeyAuth
 // @Router       /lyrics/search [get]
-func GetLyricsByText(c *gin.Context) {
+func (h *Handler) GetLyricsByText(c *gin.Context) {
 	ip := c.ClientIP()
 	logger.Info.Printf("[handlers.GetLyricsByText]: Client with IP=%s, requested to get lyrics by text", ip)
 
@@ -374,7 +434,7 @@ func GetLyricsByText(c *gin.Context) {
 
 	logger.Info.Printf("[handlers.GetLyricsByText]: Searching for lyrics containing: %s", searchText)
 
-	lyrics, err := services.GetLyricsByText(searchText, page, limit)
+	lyrics, err := h.songs.GetLyricsByText(c.Request.Context(), searchText, page, limit)
 	if err != nil {
 		logger.Error.Printf("[handlers.GetLyricsByText]: Error: %v", err)
 		handleError(c, err)
@@ -383,7 +443,7 @@ func GetLyricsByText(c *gin.Context) {
 
 	if lyrics == nil {
 		logger.Info.Printf("[handlers.GetLyricsByText]: Client with IP=%s, no lyrics found", ip)
-		c.JSON(http.StatusOK, DefaultResponse{Message: "No lyrics found."})
+		c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.no_lyrics_found")))
 		return
 	}
 
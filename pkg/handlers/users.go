@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+)
+
+// Register godoc
+// @Summary      Register an account
+// @Description  Creates an account with the viewer role in the default library. Only available when allow_registration is set in auth_params.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.Credentials  true  "Username (3-64 characters) and password (8-72 bytes)"
+// @Success      201  {object}  models.User    "The new account"
+// @Failure      400  {object}  ProblemDetails "Invalid username or password"
+// @Failure      409  {object}  ProblemDetails "Username is taken"
+// @Failure      403  {object}  ProblemDetails "Registration is closed"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /auth/register [post]
+func (h *Handler) Register(c *gin.Context) {
+	logger.Info.Printf("[handlers.Register] Client IP: %s - Request to register", c.ClientIP())
+
+	var credentials models.Credentials
+	if err := bindJSON(c, &credentials); err != nil {
+		logger.Error.Printf("[handlers.Register] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	user, err := h.users.Register(c.Request.Context(), credentials)
+	if err != nil {
+		logger.Error.Printf("[handlers.Register] Error registering: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login godoc
+// @Summary      Log in
+// @Description  Exchanges a username and password for a short-lived access token, sent as "Authorization: Bearer <token>", and a refresh token.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.Credentials  true  "Username and password"
+// @Success      200  {object}  models.TokenResponse  "Tokens"
+// @Failure      400  {object}  ProblemDetails "Invalid request body"
+// @Failure      401  {object}  ProblemDetails "Wrong username or password"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /auth/login [post]
+func (h *Handler) Login(c *gin.Context) {
+	ip := c.ClientIP()
+	logger.Info.Printf("[handlers.Login] Client IP: %s - Request to log in", ip)
+
+	var credentials models.Credentials
+	if err := bindJSON(c, &credentials); err != nil {
+		logger.Error.Printf("[handlers.Login] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	tokens, err := h.users.Login(c.Request.Context(), credentials)
+	if err != nil {
+		logger.Info.Printf("[handlers.Login] Client IP: %s - Login as %q failed: %s", ip, credentials.Username, err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RefreshToken godoc
+// @Summary      Refresh tokens
+// @Description  Trades a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one revokes all refresh tokens of the account.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshTokenRequest  true  "Refresh token"
+// @Success      200  {object}  models.TokenResponse  "Tokens"
+// @Failure      400  {object}  ProblemDetails "Invalid request body"
+// @Failure      401  {object}  ProblemDetails "Unknown, used or expired refresh token"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	logger.Info.Printf("[handlers.RefreshToken] Client IP: %s - Request to refresh tokens", c.ClientIP())
+
+	var request models.RefreshTokenRequest
+	if err := bindJSON(c, &request); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	tokens, err := h.users.Refresh(c.Request.Context(), request.RefreshToken)
+	if err != nil {
+		logger.Error.Printf("[handlers.RefreshToken] Error refreshing tokens: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revokes a refresh token. Access tokens issued with it stay valid until they expire.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshTokenRequest  true  "Refresh token"
+// @Success      200  {object}  DefaultResponse  "Logged out"
+// @Failure      400  {object}  ProblemDetails "Invalid request body"
+// @Failure      401  {object}  ProblemDetails "Unknown refresh token"
+// @Failure      429  {object}  ProblemDetails "Rate limit or daily quota exceeded"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	logger.Info.Printf("[handlers.Logout] Client IP: %s - Request to log out", c.ClientIP())
+
+	var request models.RefreshTokenRequest
+	if err := bindJSON(c, &request); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.users.Logout(c.Request.Context(), request.RefreshToken); err != nil {
+		logger.Error.Printf("[handlers.Logout] Error logging out: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDefaultResponse(text(c, "message.logged_out")))
+}
+
+// GetUsers godoc
+// @Summary      List users
+// @Description  Lists all user accounts with their roles.
+// @Tags         Users
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.User    "Users"
+// @Failure      401  {object}  ProblemDetails "Not authenticated"
+// @Failure      403  {object}  ProblemDetails "Caller is not an admin"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /users [get]
+func (h *Handler) GetUsers(c *gin.Context) {
+	logger.Info.Printf("[handlers.GetUsers] Client IP: %s - Request to list users", c.ClientIP())
+
+	users, err := h.users.GetUsers(c.Request.Context())
+	if err != nil {
+		logger.Error.Printf("[handlers.GetUsers] Error listing users: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser godoc
+// @Summary      Create a user
+// @Description  Creates an account with the given role: viewer (read), editor (read and write) or admin (everything). Accounts limited to a library cannot be admins.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        user  body      models.NewUserRequest  true  "Username, password and role"
+// @Param        Idempotency-Key  header    string  false  "Key under which retries of the request get the response to its first use"
+// @Success      201  {object}  models.User    "The new account"
+// @Failure      400  {object}  ProblemDetails "Invalid username, password, role or library"
+// @Failure      409  {object}  ProblemDetails "Username is taken or Idempotency-Key in use"
+// @Failure      401  {object}  ProblemDetails "Not authenticated"
+// @Failure      403  {object}  ProblemDetails "Caller is not an admin"
+// @Failure      422  {object}  ProblemDetails "Idempotency-Key used before for a different request"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /users [post]
+func (h *Handler) CreateUser(c *gin.Context) {
+	logger.Info.Printf("[handlers.CreateUser] Client IP: %s - Request to create a user", c.ClientIP())
+
+	var request models.NewUserRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.CreateUser] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	user, err := h.users.CreateUser(c.Request.Context(), request)
+	if err != nil {
+		logger.Error.Printf("[handlers.CreateUser] Error creating user: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUserRole godoc
+// @Summary      Change the role of a user
+// @Description  Sets the role of a user. Access tokens already issued keep the old role until they expire.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id    path      int                           true  "User ID"
+// @Param        role  body      models.UpdateUserRoleRequest  true  "New role"
+// @Success      200  {object}  models.User    "The updated account"
+// @Failure      400  {object}  ProblemDetails "Invalid ID or role, or admin role for a user limited to a library"
+// @Failure      401  {object}  ProblemDetails "Not authenticated"
+// @Failure      403  {object}  ProblemDetails "Caller is not an admin"
+// @Failure      404  {object}  ProblemDetails "User not found"
+// @Failure      500  {object}  ProblemDetails "Internal server error"
+// @Router       /users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	idParam := c.Param("id")
+	logger.Info.Printf("[handlers.UpdateUserRole] Client IP: %s - Request to change the role of user %s", c.ClientIP(), idParam)
+
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		logger.Error.Printf("[handlers.UpdateUserRole] Invalid ID format: %s", err)
+		handleError(c, utils.ErrInvalidID)
+		return
+	}
+
+	var request models.UpdateUserRoleRequest
+	if err := bindJSON(c, &request); err != nil {
+		logger.Error.Printf("[handlers.UpdateUserRole] Error binding JSON: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	user, err := h.users.UpdateUserRole(c.Request.Context(), uint(id), request.Role)
+	if err != nil {
+		logger.Error.Printf("[handlers.UpdateUserRole] Error changing role: %s", err)
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
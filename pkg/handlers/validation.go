@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"reflect"
+	"song-library/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fieldCodes names the error reported for a field that breaks one of its rules, by JSON
+// name. Fields not listed report ErrMissingRequiredField when left out and
+// ErrInvalidRequestBody otherwise.
+var fieldCodes = map[string]error{
+	"group":        utils.ErrInvalidGroup,
+	"song":         utils.ErrInvalidSongTitle,
+	"release_date": utils.ErrInvalidReleaseDate,
+	"releaseDate":  utils.ErrInvalidReleaseDate,
+	"text":         utils.ErrInvalidText,
+	"link":         utils.ErrInvalidLink,
+	"url":          utils.ErrInvalidLink,
+	"language":     utils.ErrInvalidLanguage,
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func init() {
+	// Violations are reported by the JSON name of the field, as clients know it.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bindJSON decodes the request body into request, a pointer to a DTO or to a slice of
+// them, and checks the binding rules of its fields. Fields the DTO does not have are
+// rejected. Unknown fields and rule violations are all reported at once as a
+// *utils.ValidationError, described in the language of the request.
+func bindJSON(c *gin.Context, request interface{}) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return utils.ErrFailedToParseJSON
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if err := decoder.Decode(request); err != nil {
+		return decodeError(c, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return utils.ErrFailedToParseJSON
+	}
+
+	invalid := &utils.ValidationError{}
+	addUnknownFields(c, invalid, body, reflect.TypeOf(request), "")
+	addRuleViolations(c, invalid, request)
+	if len(invalid.Fields) == 0 {
+		return nil
+	}
+	return invalid
+}
+
+// decodeError reports a body that could not be decoded, pointing at the offending field
+// where the decoder tells it.
+func decodeError(c *gin.Context, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return &utils.ValidationError{
+			Err: utils.ErrInvalidRequestBody,
+			Fields: []utils.FieldError{{
+				Pointer: "/" + strings.ReplaceAll(typeErr.Field, ".", "/"),
+				Code:    utils.ErrInvalidRequestBody.Error(),
+				Detail:  text(c, "rule.type."+jsonKind(typeErr.Type)),
+			}},
+		}
+	default:
+		return utils.ErrFailedToParseJSON
+	}
+}
+
+// addUnknownFields reports every member of the JSON value that the Go type t has no field
+// for, at any depth. Member names are matched without regard to case, as the decoder does.
+// Types that decode themselves, such as time.Time, are left to their own rules.
+func addUnknownFields(c *gin.Context, invalid *utils.ValidationError, value json.RawMessage, t reflect.Type, prefix string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(value, &items) != nil {
+			return
+		}
+		for i, item := range items {
+			addUnknownFields(c, invalid, item, t.Elem(), prefix+"/"+strconv.Itoa(i))
+		}
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if json.Unmarshal(value, &members) != nil {
+			return
+		}
+		names := make([]string, 0, len(members))
+		for name := range members {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fields := jsonFields(t)
+		for _, name := range names {
+			field, ok := lookupJSONField(fields, name)
+			if !ok {
+				if invalid.Err == nil {
+					invalid.Err = utils.ErrInvalidRequestBody
+				}
+				invalid.Fields = append(invalid.Fields, utils.FieldError{
+					Pointer: prefix + "/" + name,
+					Code:    utils.ErrInvalidRequestBody.Error(),
+					Detail:  text(c, "rule.unknown_field"),
+				})
+				continue
+			}
+			addUnknownFields(c, invalid, members[name], field.Type, prefix+"/"+name)
+		}
+	}
+}
+
+// jsonFields returns the fields of a struct type by JSON name, with those of embedded
+// structs promoted as the decoder promotes them.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			for promoted, promotedField := range jsonFields(embedded) {
+				if _, ok := fields[promoted]; !ok {
+					fields[promoted] = promotedField
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func lookupJSONField(fields map[string]reflect.StructField, name string) (reflect.StructField, bool) {
+	if field, ok := fields[name]; ok {
+		return field, true
+	}
+	for fieldName, field := range fields {
+		if strings.EqualFold(fieldName, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// addRuleViolations checks the binding rules of request, a pointer to a DTO or to a slice
+// of them.
+func addRuleViolations(c *gin.Context, invalid *utils.ValidationError, request interface{}) {
+	value := reflect.Indirect(reflect.ValueOf(request))
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			addViolations(c, invalid, value.Index(i).Interface(), "/"+strconv.Itoa(i))
+		}
+		return
+	}
+	addViolations(c, invalid, request, "")
+}
+
+// addViolations checks the rules of one DTO, whose JSON pointer in the body is prefix. The
+// first violation decides the error the request as a whole is answered with.
+func addViolations(c *gin.Context, invalid *utils.ValidationError, request interface{}, prefix string) {
+	err := binding.Validator.ValidateStruct(request)
+	var ruleErrs validator.ValidationErrors
+	if !errors.As(err, &ruleErrs) {
+		return
+	}
+
+	for _, ruleErr := range ruleErrs {
+		code, ok := fieldCodes[ruleErr.Field()]
+		if !ok {
+			code = utils.ErrInvalidRequestBody
+			if ruleErr.Tag() == "required" {
+				code = utils.ErrMissingRequiredField
+			}
+		}
+		if invalid.Err == nil {
+			invalid.Err = code
+		}
+		invalid.Fields = append(invalid.Fields, utils.FieldError{
+			Pointer: prefix + jsonPointer(ruleErr.Namespace()),
+			Code:    code.Error(),
+			Detail:  ruleDetail(c, ruleErr),
+		})
+	}
+}
+
+// jsonPointer turns a validator namespace such as "MergeSongsRequest.source_ids[1]" into
+// the JSON pointer "/source_ids/1".
+func jsonPointer(namespace string) string {
+	_, path, _ := strings.Cut(namespace, ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	return "/" + strings.ReplaceAll(path, ".", "/")
+}
+
+// ruleDetail says in words which rule the value broke, with the rule.* texts of the
+// message catalogues.
+func ruleDetail(c *gin.Context, ruleErr validator.FieldError) string {
+	switch ruleErr.Tag() {
+	case "required", "url", "bcp47_language_tag":
+		return text(c, "rule."+ruleErr.Tag())
+	case "max", "min":
+		if ruleErr.Kind() == reflect.String {
+			return text(c, "rule."+ruleErr.Tag()+".string", "{param}", ruleErr.Param())
+		}
+		return text(c, "rule."+ruleErr.Tag()+".items", "{param}", ruleErr.Param())
+	case "datetime":
+		return text(c, "rule.datetime", "{param}", ruleErr.Param())
+	case "oneof":
+		return text(c, "rule.oneof", "{param}", strings.ReplaceAll(ruleErr.Param(), " ", ", "))
+	default:
+		return text(c, "rule.other", "{rule}", ruleErr.Tag())
+	}
+}
+
+// jsonKind names the JSON type a Go type is decoded from, as in the rule.type.* texts.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"song-library/pkg/handlers"
+	"song-library/utils"
+	"testing"
+)
+
+func TestBindJSONReportsEveryField(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+
+	for _, test := range []struct {
+		name, method, target, key, body string
+		code                            error
+		fields                          []utils.FieldError
+	}{
+		{
+			name: "unknown fields at any depth", method: http.MethodPost, target: "/songs/batch", key: writerKey,
+			body: `{"extra":1,"operations":[{"op":"delete","id":1},{"op":"create","group":"Muse","song":"Hysteria","colour":"red"}]}`,
+			code: utils.ErrInvalidRequestBody,
+			fields: []utils.FieldError{
+				{Pointer: "/extra", Code: utils.ErrInvalidRequestBody.Error()},
+				{Pointer: "/operations/1/colour", Code: utils.ErrInvalidRequestBody.Error()},
+			},
+		},
+		{
+			name: "unknown fields in an array body", method: http.MethodPost, target: "/API/info/bulk", key: adminKey,
+			body: `[{"group":"Muse","song":"Uprising"},{"group":"Muse","song":"Hysteria","bogus":true}]`,
+			code: utils.ErrInvalidRequestBody,
+			fields: []utils.FieldError{
+				{Pointer: "/1/bogus", Code: utils.ErrInvalidRequestBody.Error()},
+			},
+		},
+		{
+			name: "several violations", method: http.MethodPut, target: "/songs/1", key: writerKey,
+			body: `{"group":"","release_date":"2001-01-01","link":"not a link","language":"not a language"}`,
+			code: utils.ErrInvalidGroup,
+			fields: []utils.FieldError{
+				{Pointer: "/group", Code: utils.ErrInvalidGroup.Error()},
+				{Pointer: "/song", Code: utils.ErrInvalidSongTitle.Error()},
+				{Pointer: "/release_date", Code: utils.ErrInvalidReleaseDate.Error()},
+				{Pointer: "/link", Code: utils.ErrInvalidLink.Error()},
+				{Pointer: "/language", Code: utils.ErrInvalidLanguage.Error()},
+			},
+		},
+		{
+			name: "unknown fields and violations together", method: http.MethodPost, target: "/songs/batch", key: writerKey,
+			body: `{"operations":[{"op":"rename","id":1,"title":"Uprising"}]}`,
+			code: utils.ErrInvalidRequestBody,
+			fields: []utils.FieldError{
+				{Pointer: "/operations/0/title", Code: utils.ErrInvalidRequestBody.Error()},
+				{Pointer: "/operations/0/op", Code: utils.ErrInvalidRequestBody.Error()},
+			},
+		},
+		{
+			name: "type mismatch", method: http.MethodPost, target: "/songs/batch", key: writerKey,
+			body: `{"atomic":"yes","operations":[{"op":"delete","id":1}]}`,
+			code: utils.ErrInvalidRequestBody,
+			fields: []utils.FieldError{
+				{Pointer: "/atomic", Code: utils.ErrInvalidRequestBody.Error()},
+			},
+		},
+		{
+			name: "nested type mismatch", method: http.MethodPost, target: "/songs/batch", key: writerKey,
+			body: `{"operations":[{"op":"delete","id":"one"}]}`,
+			code: utils.ErrInvalidRequestBody,
+			fields: []utils.FieldError{
+				{Pointer: "/operations/0/id", Code: utils.ErrInvalidRequestBody.Error()},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response := serve(t, router, test.method, test.target, test.key, test.body)
+			var problem handlers.ProblemDetails
+			if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusBadRequest || problem.Code != test.code.Error() {
+				t.Fatalf("%s %s = %d %s, want 400 %s", test.method, test.target, response.Code, response.Body, test.code)
+			}
+			var fields []utils.FieldError
+			for _, field := range problem.Errors {
+				if field.Detail == "" {
+					t.Errorf("error at %s has no detail", field.Pointer)
+				}
+				fields = append(fields, utils.FieldError{Pointer: field.Pointer, Code: field.Code})
+			}
+			if !reflect.DeepEqual(fields, test.fields) {
+				t.Errorf("errors = %+v, want %+v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestBindJSONRejectsMalformedBodies(t *testing.T) {
+	router, repos := newTestServer(t)
+	seedSong(t, repos)
+
+	for _, body := range []string{
+		`{"group":"Muse","song":"Uprising"`,
+		`{"group":"Muse","song":"Uprising"} {"group":"Muse"}`,
+		`not json`,
+	} {
+		response := serve(t, router, http.MethodPut, "/songs/1", writerKey, body)
+		var problem handlers.ProblemDetails
+		if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil || response.Code != http.StatusBadRequest || problem.Code != utils.ErrFailedToParseJSON.Error() {
+			t.Errorf("PUT /songs/1 %s = %d %s, want 400 %s", body, response.Code, response.Body, utils.ErrFailedToParseJSON)
+		}
+	}
+
+	// Member names match without regard to case, as the decoder matches them.
+	if response := serve(t, router, http.MethodPut, "/songs/1", writerKey, `{"Group":"Muse","SONG":"Uprising"}`); response.Code != http.StatusOK {
+		t.Errorf("PUT /songs/1 with capitalised names = %d %s, want 200", response.Code, response.Body)
+	}
+}
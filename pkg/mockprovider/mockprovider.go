@@ -0,0 +1,262 @@
+package mockprovider
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"math/rand"
+	"net/http"
+	"song-library/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRequestLogSize is how many requests are kept for /_mock/requests unless the
+// settings say otherwise.
+const DefaultRequestLogSize = 1000
+
+// Settings controls how the mock provider misbehaves. Rates are probabilities in [0, 1].
+type Settings struct {
+	LatencyMs      int     `json:"latency_ms"`       // Fixed delay added to every lookup
+	JitterMs       int     `json:"jitter_ms"`        // Random extra delay up to this value
+	ErrorRate      float64 `json:"error_rate"`       // Share of lookups answered with ErrorStatus
+	ErrorStatus    int     `json:"error_status"`     // Status code of injected errors
+	MalformedRate  float64 `json:"malformed_rate"`   // Share of lookups answered with broken JSON
+	RequestLogSize int     `json:"request_log_size"` // Most recent requests kept, 0 for DefaultRequestLogSize
+}
+
+func (s Settings) Validate() error {
+	if s.LatencyMs < 0 || s.JitterMs < 0 {
+		return errors.New("latency and jitter must not be negative")
+	}
+	if s.RequestLogSize < 0 {
+		return errors.New("request log size must not be negative")
+	}
+	if s.ErrorRate < 0 || s.ErrorRate > 1 || s.MalformedRate < 0 || s.MalformedRate > 1 {
+		return errors.New("rates must be between 0 and 1")
+	}
+	return nil
+}
+
+func (s Settings) requestLogSize() int {
+	if s.RequestLogSize == 0 {
+		return DefaultRequestLogSize
+	}
+	return s.RequestLogSize
+}
+
+type RecordedRequest struct {
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query"`
+	RemoteAddr string    `json:"remote_addr"`
+	UserAgent  string    `json:"user_agent"`
+	Status     int       `json:"status"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Provider serves song details from a fixture set the same way the real metadata API does.
+type Provider struct {
+	mu       sync.RWMutex
+	fixtures map[string]models.SongDetail
+	settings Settings
+	requests requestLog
+	rnd      *rand.Rand
+}
+
+func New(fixtures []models.SongDetail, settings Settings) *Provider {
+	p := &Provider{
+		settings: settings,
+		requests: requestLog{limit: settings.requestLogSize()},
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	p.SetFixtures(fixtures)
+	return p
+}
+
+func (p *Provider) SetFixtures(fixtures []models.SongDetail) {
+	index := make(map[string]models.SongDetail, len(fixtures))
+	for _, fixture := range fixtures {
+		index[fixtureKey(fixture.Group, fixture.Song)] = fixture
+	}
+
+	p.mu.Lock()
+	p.fixtures = index
+	p.mu.Unlock()
+}
+
+// Routes returns the handler serving the lookup endpoint at /info and the control
+// endpoints under /_mock.
+func (p *Provider) Routes() *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	r.GET("/info", p.record, p.lookup)
+
+	control := r.Group("/_mock")
+	{
+		control.GET("/requests", p.listRequests)
+		control.DELETE("/requests", p.clearRequests)
+		control.GET("/settings", p.getSettings)
+		control.PUT("/settings", p.putSettings)
+		control.PUT("/fixtures", p.putFixtures)
+	}
+	return r
+}
+
+func (p *Provider) record(c *gin.Context) {
+	received := time.Now()
+	c.Next()
+
+	p.mu.Lock()
+	p.requests.add(RecordedRequest{
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		Query:      c.Request.URL.RawQuery,
+		RemoteAddr: c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Status:     c.Writer.Status(),
+		ReceivedAt: received,
+	})
+	p.mu.Unlock()
+}
+
+func (p *Provider) lookup(c *gin.Context) {
+	p.mu.Lock()
+	settings := p.settings
+	delay := time.Duration(settings.LatencyMs) * time.Millisecond
+	if settings.JitterMs > 0 {
+		delay += time.Duration(p.rnd.Intn(settings.JitterMs)) * time.Millisecond
+	}
+	failRoll, malformedRoll := p.rnd.Float64(), p.rnd.Float64()
+	p.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+
+	if failRoll < settings.ErrorRate {
+		status := settings.ErrorStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": "injected failure"})
+		return
+	}
+
+	group := c.Query("group")
+	song := c.Query("song")
+	if group == "" || song == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group and song are required"})
+		return
+	}
+
+	p.mu.RLock()
+	detail, ok := p.fixtures[fixtureKey(group, song)]
+	p.mu.RUnlock()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "song not found"})
+		return
+	}
+
+	if malformedRoll < settings.MalformedRate {
+		c.Data(http.StatusOK, "application/json", []byte(`{"releaseDate": "`+detail.ReleaseDate+`", "text": `))
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (p *Provider) listRequests(c *gin.Context) {
+	p.mu.RLock()
+	requests := p.requests.list()
+	p.mu.RUnlock()
+
+	c.JSON(http.StatusOK, requests)
+}
+
+func (p *Provider) clearRequests(c *gin.Context) {
+	p.mu.Lock()
+	p.requests = requestLog{limit: p.requests.limit}
+	p.mu.Unlock()
+
+	c.Status(http.StatusNoContent)
+}
+
+func (p *Provider) getSettings(c *gin.Context) {
+	p.mu.RLock()
+	settings := p.settings
+	p.mu.RUnlock()
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (p *Provider) putSettings(c *gin.Context) {
+	var settings Settings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p.mu.Lock()
+	p.settings = settings
+	p.requests.resize(settings.requestLogSize())
+	p.mu.Unlock()
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (p *Provider) putFixtures(c *gin.Context) {
+	var fixtures []models.SongDetail
+	if err := c.ShouldBindJSON(&fixtures); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p.SetFixtures(fixtures)
+	c.JSON(http.StatusOK, gin.H{"fixtures": len(fixtures)})
+}
+
+// requestLog is a ring buffer of the last limit recorded requests.
+type requestLog struct {
+	entries []RecordedRequest
+	next    int // Oldest entry once the log is full
+	limit   int
+}
+
+func (l *requestLog) add(request RecordedRequest) {
+	if len(l.entries) < l.limit {
+		l.entries = append(l.entries, request)
+		return
+	}
+	l.entries[l.next] = request
+	l.next = (l.next + 1) % l.limit
+}
+
+// list returns a copy of the entries, oldest first.
+func (l *requestLog) list() []RecordedRequest {
+	requests := make([]RecordedRequest, 0, len(l.entries))
+	requests = append(requests, l.entries[l.next:]...)
+	return append(requests, l.entries[:l.next]...)
+}
+
+// resize keeps the newest limit entries.
+func (l *requestLog) resize(limit int) {
+	entries := l.list()
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	*l = requestLog{entries: entries, limit: limit}
+}
+
+func fixtureKey(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
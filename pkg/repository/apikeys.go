@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Order("id").Find(&keys).Error; err != nil {
+		logger.Error.Printf("[repository.GetAPIKeys]: Error finding API keys: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	return r.findAPIKey(ctx, "id = ?", id)
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return r.findAPIKey(ctx, "key_hash = ?", keyHash)
+}
+
+func (r *apiKeyRepository) findAPIKey(ctx context.Context, query string, arg interface{}) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error.Printf("[repository.findAPIKey]: Error finding API key: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) AddAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		logger.Error.Printf("[repository.AddAPIKey]: Error adding API key: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	err := r.db.WithContext(ctx).Model(key).
+		Select("prefix", "key_hash", "expires_at", "revoked_at", "updated_at").
+		Updates(key).Error
+	if err != nil {
+		logger.Error.Printf("[repository.UpdateAPIKey]: Error updating API key: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error; err != nil {
+		logger.Error.Printf("[repository.TouchAPIKey]: Error updating API key: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
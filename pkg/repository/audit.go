@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// AddAuditEntry reads the hash of the last entry from audit_chain_head with an UPDATE,
+// which locks the row until the transaction ends. Concurrent appends wait for each other,
+// and one inside a repeatable read or serializable transaction that began before another
+// append committed fails instead of linking to the same entry.
+func (r *auditRepository) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var heads []struct{ Hash string }
+		if err := tx.Raw("UPDATE audit_chain_head SET hash = hash WHERE id = 1 RETURNING hash").Scan(&heads).Error; err != nil {
+			return err
+		}
+		if len(heads) != 1 {
+			return errors.New("audit_chain_head has no row")
+		}
+
+		// Stored times keep microseconds, so the hash is taken over no more than that.
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Seal(heads[0].Hash)
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE audit_chain_head SET hash = ? WHERE id = 1", entry.Hash).Error
+	})
+	if err != nil {
+		logger.Error.Printf("[repository.AddAuditEntry]: Error adding audit entry: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *auditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditEntry{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.Route != "" {
+		query = query.Where("route = ?", filter.Route)
+	}
+	if filter.SongID != 0 {
+		query = query.Where("song_id = ?", filter.SongID)
+	}
+	if filter.LibraryID != 0 {
+		query = query.Where("library_id = ?", filter.LibraryID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+
+	entries := []models.AuditEntry{}
+	err := query.Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&entries).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetAuditEntries]: Error finding audit entries: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return entries, nil
+}
+
+func (r *auditRepository) GetAuditEntriesAfterID(ctx context.Context, afterID uint, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	if err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&entries).Error; err != nil {
+		logger.Error.Printf("[repository.GetAuditEntriesAfterID]: Error finding audit entries: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return entries, nil
+}
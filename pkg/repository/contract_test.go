@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"database/sql"
+	"os"
+	"song-library/configs"
+	"song-library/db"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/repositorytest"
+	"testing"
+)
+
+func TestSQLiteSongContract(t *testing.T) {
+	repositorytest.RunSongContract(t, func(t *testing.T) repository.Repositories {
+		return openDatabase(t, models.DatabaseParams{Driver: models.DriverSQLite, SQLitePath: ":memory:"})
+	})
+}
+
+// TestPostgresSongContract runs against the database named by TEST_POSTGRES_HOST,
+// TEST_POSTGRES_PORT, TEST_POSTGRES_USER, TEST_POSTGRES_DATABASE and DB_PASSWORD. Every
+// test reverts and reapplies all migrations, so the database must be a scratch one.
+func TestPostgresSongContract(t *testing.T) {
+	params := postgresParams(t)
+	repositorytest.RunSongContract(t, func(t *testing.T) repository.Repositories {
+		return openScratchDatabase(t, params)
+	})
+}
+
+func TestSQLiteAuditContract(t *testing.T) {
+	repositorytest.RunAuditContract(t, func(t *testing.T) (repository.Repositories, repository.UnitOfWork) {
+		repos := openDatabase(t, models.DatabaseParams{Driver: models.DriverSQLite, SQLitePath: ":memory:"})
+		return repos, repository.NewUnitOfWork(db.GetDBConn(), sql.LevelDefault)
+	})
+}
+
+// TestPostgresAuditContract runs its units of work under repeatable read, where each
+// transaction reads from a snapshot taken at its first statement.
+func TestPostgresAuditContract(t *testing.T) {
+	params := postgresParams(t)
+	repositorytest.RunAuditContract(t, func(t *testing.T) (repository.Repositories, repository.UnitOfWork) {
+		repos := openScratchDatabase(t, params)
+		return repos, repository.NewUnitOfWork(db.GetDBConn(), sql.LevelRepeatableRead)
+	})
+}
+
+// postgresParams returns the test database named by the environment, or skips the test
+// if there is none.
+func postgresParams(t *testing.T) models.DatabaseParams {
+	t.Helper()
+	host := os.Getenv("TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("TEST_POSTGRES_HOST is not set")
+	}
+	return models.DatabaseParams{
+		Driver:   models.DriverPostgres,
+		Host:     host,
+		Port:     os.Getenv("TEST_POSTGRES_PORT"),
+		User:     os.Getenv("TEST_POSTGRES_USER"),
+		Database: os.Getenv("TEST_POSTGRES_DATABASE"),
+	}
+}
+
+// openScratchDatabase connects to the database and reverts and reapplies all migrations,
+// which leaves it empty.
+func openScratchDatabase(t *testing.T, params models.DatabaseParams) repository.Repositories {
+	t.Helper()
+	repos := openDatabase(t, params)
+	if err := db.MigrateTo(0); err != nil {
+		t.Fatalf("reverting migrations: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+	return repos
+}
+
+// openDatabase connects to the database and applies the migrations.
+func openDatabase(t *testing.T, params models.DatabaseParams) repository.Repositories {
+	t.Helper()
+	configs.AppSettings.DatabaseParams = params
+	if err := db.ConnectToDB(); err != nil {
+		t.Fatalf("connecting to %s: %v", params.Driver, err)
+	}
+	t.Cleanup(func() {
+		if err := db.CloseDBConn(); err != nil {
+			t.Errorf("closing %s: %v", params.Driver, err)
+		}
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+	return repository.NewRepositories(db.GetDBConn())
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+// GROUP is a reserved word in SQL, so the "group" column of songs and song_details is
+// always written through clause.Column and quoted by the dialect in use.
+var groupColumn = clause.Column{Name: "group"}
+
+func groupIs(group string) clause.Eq {
+	return clause.Eq{Column: groupColumn, Value: group}
+}
+
+func songIs(song string) clause.Eq {
+	return clause.Eq{Column: clause.Column{Name: "song"}, Value: song}
+}
+
+// quoteColumn quotes a table-qualified column for use in raw SELECT lists.
+func quoteColumn(db *gorm.DB, table, column string) string {
+	return db.Statement.Quote(clause.Column{Table: table, Name: column})
+}
+
+// containsText matches rows whose column contains the text, with the case of letters
+// compared as written. LIKE does that in PostgreSQL, but SQLite's LIKE ignores the case of
+// ASCII letters, so SQLite looks the text up with instr instead. Wildcards in the text are
+// escaped so they match literally.
+func containsText(db *gorm.DB, column, text string) clause.Expression {
+	if db.Dialector.Name() == "sqlite" {
+		return clause.Expr{
+			SQL:  "instr(?, ?) > 0",
+			Vars: []interface{}{clause.Column{Name: column}, text},
+		}
+	}
+
+	pattern := "%" + likeEscaper.Replace(text) + "%"
+	return clause.Expr{
+		SQL:  "? LIKE ? ESCAPE '\\'",
+		Vars: []interface{}{clause.Column{Name: column}, pattern},
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
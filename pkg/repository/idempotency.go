@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// ReserveIdempotencyKey inserts the record unless the primary key is taken, which decides
+// between concurrent requests with the same key. A record released between the failed
+// insert and reading it back lets the insert be tried again.
+func (r *idempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	db := r.db.WithContext(ctx)
+	err := db.Where("client = ? AND key = ? AND expires_at <= ?", record.Client, record.Key, record.CreatedAt).
+		Delete(&models.IdempotencyRecord{}).Error
+	for attempt := 0; err == nil && attempt < 2; attempt++ {
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if err = result.Error; err != nil || result.RowsAffected == 1 {
+			break
+		}
+
+		var existing models.IdempotencyRecord
+		err = db.Where("client = ? AND key = ?", record.Client, record.Key).First(&existing).Error
+		if err == nil {
+			return &existing, nil
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+	}
+	if err != nil {
+		logger.Error.Printf("[repository.ReserveIdempotencyKey]: Error reserving idempotency key: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return nil, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	err := r.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Where("client = ? AND key = ?", record.Client, record.Key).
+		Select("status", "headers", "body").
+		Updates(record).Error
+	if err != nil {
+		logger.Error.Printf("[repository.CompleteIdempotencyKey]: Error saving response: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, client, key string) error {
+	err := r.db.WithContext(ctx).Where("client = ? AND key = ?", client, key).
+		Delete(&models.IdempotencyRecord{}).Error
+	if err != nil {
+		logger.Error.Printf("[repository.ReleaseIdempotencyKey]: Error releasing idempotency key: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyRecord{})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.DeleteExpiredIdempotencyRecords]: Error deleting expired records: %s\n", result.Error.Error())
+		return 0, utils.ErrDatabaseConnectionFailed
+	}
+	return result.RowsAffected, nil
+}
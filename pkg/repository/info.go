@@ -1,26 +1,34 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"gorm.io/gorm"
-	"song-library/db"
 	"song-library/logger"
 	"song-library/models"
 	"song-library/utils"
 )
 
-func GetInfoByGroup(group string) ([]models.SongDetail, error) {
+type songDetailRepository struct {
+	db *gorm.DB
+}
+
+func NewSongDetailRepository(db *gorm.DB) SongDetailRepository {
+	return &songDetailRepository{db: db}
+}
+
+func (r *songDetailRepository) GetInfoByGroup(ctx context.Context, group string) ([]models.SongDetail, error) {
 	var songDetails []models.SongDetail
-	if err := db.GetDBConn().Where("\"group\" = ?", group).Find(&songDetails).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where(groupIs(group)).Find(&songDetails).Error; err != nil {
 		logger.Error.Printf("[repository.GetInfoByGroup]: Error finding songs: %s\n", err.Error())
-		return nil, err
+		return nil, utils.ErrDatabaseConnectionFailed
 	}
 	return songDetails, nil
 }
 
-func GetInfoBySong(song string) (bool, error) {
+func (r *songDetailRepository) GetInfoBySong(ctx context.Context, song string) (bool, error) {
 	var count int64
-	err := db.GetDBConn().Model(&models.SongDetail{}).Where("song = ?", song).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.SongDetail{}).Where("song = ?", song).Count(&count).Error
 	if err != nil {
 		logger.Error.Printf("[repository.GetInfoBySong]: Error finding songs: %s\n", err.Error())
 		return false, utils.ErrDatabaseConnectionFailed
@@ -28,10 +36,10 @@ func GetInfoBySong(song string) (bool, error) {
 	return count > 0, nil
 }
 
-func GetSongDetail(group, song string) (models.SongDetail, error) {
+func (r *songDetailRepository) GetSongDetail(ctx context.Context, group, song string) (models.SongDetail, error) {
 	var songDetail models.SongDetail
-	err := db.GetDBConn().Model(&models.SongDetail{}).
-		Where("\"group\" = ? AND song = ?", group, song).
+	err := r.db.WithContext(ctx).Model(&models.SongDetail{}).
+		Where(groupIs(group)).Where(songIs(song)).
 		First(&songDetail).Error
 
 	if err != nil {
@@ -43,3 +51,99 @@ func GetSongDetail(group, song string) (models.SongDetail, error) {
 	}
 	return songDetail, nil
 }
+
+func (r *songDetailRepository) SongDetailExists(ctx context.Context, group, song string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SongDetail{}).
+		Where(groupIs(group)).Where(songIs(song)).
+		Count(&count).Error
+	if err != nil {
+		logger.Error.Printf("[repository.SongDetailExists]: Error checking song detail: %s\n", err.Error())
+		return false, utils.ErrDatabaseConnectionFailed
+	}
+	return count > 0, nil
+}
+
+func (r *songDetailRepository) AddSongDetail(ctx context.Context, songDetail *models.SongDetail) error {
+	if err := r.db.WithContext(ctx).Create(songDetail).Error; err != nil {
+		logger.Error.Printf("[repository.AddSongDetail]: Error adding song detail: %s\n", err.Error())
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return utils.ErrSongAlreadyExists
+		}
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *songDetailRepository) UpdateSongDetail(ctx context.Context, group, song string, songDetail *models.SongDetail) error {
+	result := r.db.WithContext(ctx).Model(&models.SongDetail{}).
+		Where(groupIs(group)).Where(songIs(song)).
+		Updates(songDetailColumns(songDetail))
+	if result.Error != nil {
+		logger.Error.Printf("[repository.UpdateSongDetail]: Error updating song detail: %s\n", result.Error.Error())
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return utils.ErrSongAlreadyExists
+		}
+		return utils.ErrDatabaseConnectionFailed
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrSongNotFound
+	}
+	return nil
+}
+
+func (r *songDetailRepository) DeleteSongDetail(ctx context.Context, group, song string) error {
+	result := r.db.WithContext(ctx).Where(groupIs(group)).Where(songIs(song)).Delete(&models.SongDetail{})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.DeleteSongDetail]: Error deleting song detail: %s\n", result.Error.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrSongNotFound
+	}
+	return nil
+}
+
+// UpsertSongDetails creates or replaces the given song details in a single transaction
+// and reports how many rows were created and updated. If another request creates one of
+// them first, nothing is written and ErrSongAlreadyExists is returned.
+func (r *songDetailRepository) UpsertSongDetails(ctx context.Context, songDetails []models.SongDetail) (created, updated int, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range songDetails {
+			detail := &songDetails[i]
+			result := tx.Model(&models.SongDetail{}).
+				Where(groupIs(detail.Group)).Where(songIs(detail.Song)).
+				Updates(songDetailColumns(detail))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				updated++
+				continue
+			}
+			if err := tx.Create(detail).Error; err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error.Printf("[repository.UpsertSongDetails]: Error upserting song details: %s\n", err.Error())
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return 0, 0, utils.ErrSongAlreadyExists
+		}
+		return 0, 0, utils.ErrDatabaseConnectionFailed
+	}
+	return created, updated, nil
+}
+
+func songDetailColumns(songDetail *models.SongDetail) map[string]interface{} {
+	return map[string]interface{}{
+		"group":        songDetail.Group,
+		"song":         songDetail.Song,
+		"release_date": songDetail.ReleaseDate,
+		"text":         songDetail.Text,
+		"link":         songDetail.Link,
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+)
+
+type libraryRepository struct {
+	db *gorm.DB
+}
+
+func NewLibraryRepository(db *gorm.DB) LibraryRepository {
+	return &libraryRepository{db: db}
+}
+
+func (r *libraryRepository) GetLibraries(ctx context.Context) ([]models.Library, error) {
+	var libraries []models.Library
+	if err := r.db.WithContext(ctx).Order("id").Find(&libraries).Error; err != nil {
+		logger.Error.Printf("[repository.GetLibraries]: Error finding libraries: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return libraries, nil
+}
+
+func (r *libraryRepository) GetLibraryByID(ctx context.Context, id uint) (*models.Library, error) {
+	return r.findLibrary(ctx, "id = ?", id)
+}
+
+func (r *libraryRepository) GetLibraryBySlug(ctx context.Context, slug string) (*models.Library, error) {
+	return r.findLibrary(ctx, "slug = ?", slug)
+}
+
+func (r *libraryRepository) findLibrary(ctx context.Context, query string, arg interface{}) (*models.Library, error) {
+	var library models.Library
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&library).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error.Printf("[repository.findLibrary]: Error finding library: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &library, nil
+}
+
+func (r *libraryRepository) AddLibrary(ctx context.Context, library *models.Library) error {
+	if err := r.db.WithContext(ctx).Create(library).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return utils.ErrLibraryAlreadyExists
+		}
+		logger.Error.Printf("[repository.AddLibrary]: Error adding library: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+// inLibrary limits a query on songs to the library of ctx, if it names one.
+func inLibrary(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if libraryID := models.LibraryFromContext(ctx); libraryID != 0 {
+			return db.Where("songs.library_id = ?", libraryID)
+		}
+		return db
+	}
+}
+
+// libraryOf returns the library a song added in ctx belongs to.
+func libraryOf(ctx context.Context) uint {
+	if libraryID := models.LibraryFromContext(ctx); libraryID != 0 {
+		return libraryID
+	}
+	return models.DefaultLibraryID
+}
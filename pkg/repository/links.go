@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+)
+
+type linkRepository struct {
+	db *gorm.DB
+}
+
+func NewLinkRepository(db *gorm.DB) LinkRepository {
+	return &linkRepository{db: db}
+}
+
+func (r *linkRepository) GetLinksBySongID(ctx context.Context, songID uint) ([]models.SongLink, error) {
+	var links []models.SongLink
+	if err := r.db.WithContext(ctx).Where("song_id = ?", songID).Order("id").Find(&links).Error; err != nil {
+		logger.Error.Printf("[repository.GetLinksBySongID]: Error finding links: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return links, nil
+}
+
+func (r *linkRepository) GetLinkByID(ctx context.Context, songID, linkID uint) (*models.SongLink, error) {
+	var link models.SongLink
+	err := r.db.WithContext(ctx).Where("id = ? AND song_id = ?", linkID, songID).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrLinkNotFound
+		}
+		logger.Error.Printf("[repository.GetLinkByID]: Error finding link: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &link, nil
+}
+
+func (r *linkRepository) LinkExists(ctx context.Context, songID uint, url string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SongLink{}).Where("song_id = ? AND url = ?", songID, url).Count(&count).Error
+	if err != nil {
+		logger.Error.Printf("[repository.LinkExists]: Error checking link: %s\n", err.Error())
+		return false, utils.ErrDatabaseConnectionFailed
+	}
+	return count > 0, nil
+}
+
+func (r *linkRepository) AddLink(ctx context.Context, link *models.SongLink) error {
+	if err := r.db.WithContext(ctx).Create(link).Error; err != nil {
+		logger.Error.Printf("[repository.AddLink]: Error adding link: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteLink(ctx context.Context, songID, linkID uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND song_id = ?", linkID, songID).Delete(&models.SongLink{})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.DeleteLink]: Error deleting link: %s\n", result.Error.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r *linkRepository) DeleteLinksBySongID(ctx context.Context, songID uint) error {
+	if err := r.db.WithContext(ctx).Where("song_id = ?", songID).Delete(&models.SongLink{}).Error; err != nil {
+		logger.Error.Printf("[repository.DeleteLinksBySongID]: Error deleting links: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+// GetLinksAfterID returns up to limit links of songs that are not deleted, ordered by ID and
+// starting after the given one, so all links can be walked in batches.
+func (r *linkRepository) GetLinksAfterID(ctx context.Context, afterID uint, limit int) ([]models.SongLink, error) {
+	var links []models.SongLink
+	err := r.db.WithContext(ctx).
+		Joins("JOIN songs ON songs.id = song_links.song_id AND songs.deleted_at IS NULL").
+		Where("song_links.id > ?", afterID).
+		Order("song_links.id").
+		Limit(limit).
+		Find(&links).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetLinksAfterID]: Error finding links: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return links, nil
+}
+
+func (r *linkRepository) UpdateLinkHealth(ctx context.Context, link *models.SongLink) error {
+	err := r.db.WithContext(ctx).Model(&models.SongLink{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+		"status_code":     link.StatusCode,
+		"last_error":      link.LastError,
+		"last_checked_at": link.LastCheckedAt,
+		"failure_streak":  link.FailureStreak,
+	}).Error
+	if err != nil {
+		logger.Error.Printf("[repository.UpdateLinkHealth]: Error updating link health: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *linkRepository) GetBrokenLinks(ctx context.Context, minFailures, page, limit int) ([]models.BrokenLink, error) {
+	links := []models.BrokenLink{}
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).Model(&models.SongLink{}).
+		Select("song_links.*, "+quoteColumn(r.db, "songs", "group")+", songs.song").
+		Joins("JOIN songs ON songs.id = song_links.song_id AND songs.deleted_at IS NULL").
+		Scopes(inLibrary(ctx)).
+		Where("song_links.failure_streak >= ?", minFailures).
+		Order("song_links.failure_streak DESC, song_links.id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&links).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetBrokenLinks]: Error finding broken links: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return links, nil
+}
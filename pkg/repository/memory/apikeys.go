@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"sort"
+	"time"
+)
+
+type apiKeyRepository struct {
+	store *Store
+}
+
+func NewAPIKeyRepository(store *Store) repository.APIKeyRepository {
+	return &apiKeyRepository{store: store}
+}
+
+func (r *apiKeyRepository) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(r.store.apiKeys))
+	for _, key := range r.store.apiKeys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return nil, nil
+	}
+	key = copyAPIKey(key)
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, key := range r.store.apiKeys {
+		if key.KeyHash == keyHash {
+			key = copyAPIKey(key)
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *apiKeyRepository) AddAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastAPIKeyID++
+	key.ID = r.store.lastAPIKeyID
+	now := time.Now()
+	key.CreatedAt, key.UpdatedAt = now, now
+	r.store.apiKeys[key.ID] = copyAPIKey(*key)
+	return nil
+}
+
+func (r *apiKeyRepository) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.apiKeys[key.ID]
+	if !ok {
+		return nil
+	}
+	updated := copyAPIKey(*key)
+	existing.Prefix = updated.Prefix
+	existing.KeyHash = updated.KeyHash
+	existing.ExpiresAt = updated.ExpiresAt
+	existing.RevokedAt = updated.RevokedAt
+	existing.UpdatedAt = updated.UpdatedAt
+	r.store.apiKeys[key.ID] = existing
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if key, ok := r.store.apiKeys[id]; ok {
+		key.LastUsedAt = &usedAt
+		r.store.apiKeys[id] = key
+	}
+	return nil
+}
+
+func copyAPIKey(key models.APIKey) models.APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	for _, t := range []**time.Time{&key.LastUsedAt, &key.ExpiresAt, &key.RevokedAt} {
+		if *t != nil {
+			copied := **t
+			*t = &copied
+		}
+	}
+	if key.LibraryID != nil {
+		libraryID := *key.LibraryID
+		key.LibraryID = &libraryID
+	}
+	return key
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"time"
+)
+
+type auditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) repository.AuditRepository {
+	return &auditRepository{store: store}
+}
+
+func (r *auditRepository) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	prevHash := ""
+	if n := len(r.store.audit); n > 0 {
+		prevHash = r.store.audit[n-1].Hash
+	}
+	entry.ID = uint(len(r.store.audit) + 1)
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Seal(prevHash)
+	r.store.audit = append(r.store.audit, copyAuditEntry(*entry))
+	return nil
+}
+
+func (r *auditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matched []models.AuditEntry
+	for i := len(r.store.audit) - 1; i >= 0; i-- {
+		entry := r.store.audit[i]
+		if (filter.Actor != "" && entry.Actor != filter.Actor) ||
+			(filter.Method != "" && entry.Method != filter.Method) ||
+			(filter.Route != "" && entry.Route != filter.Route) ||
+			(filter.SongID != 0 && (entry.SongID == nil || *entry.SongID != filter.SongID)) ||
+			(filter.LibraryID != 0 && entry.LibraryID != filter.LibraryID) ||
+			(filter.From != nil && entry.CreatedAt.Before(*filter.From)) ||
+			(filter.To != nil && !entry.CreatedAt.Before(*filter.To)) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	start, end, ok := paginate(len(matched), filter.Page, filter.Limit)
+	if !ok {
+		return []models.AuditEntry{}, nil
+	}
+	entries := make([]models.AuditEntry, 0, end-start)
+	for _, entry := range matched[start:end] {
+		entries = append(entries, copyAuditEntry(entry))
+	}
+	return entries, nil
+}
+
+func (r *auditRepository) GetAuditEntriesAfterID(ctx context.Context, afterID uint, limit int) ([]models.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// IDs are positions in the chain, starting at 1.
+	var entries []models.AuditEntry
+	for i := int(afterID); i < len(r.store.audit) && len(entries) < limit; i++ {
+		entries = append(entries, copyAuditEntry(r.store.audit[i]))
+	}
+	return entries, nil
+}
+
+func copyAuditEntry(entry models.AuditEntry) models.AuditEntry {
+	if entry.SongID != nil {
+		songID := *entry.SongID
+		entry.SongID = &songID
+	}
+	for _, snapshot := range []**models.Song{&entry.Before, &entry.After} {
+		if *snapshot != nil {
+			song := **snapshot
+			links := song.Links
+			song.Links = nil
+			for _, link := range links {
+				song.Links = append(song.Links, *copyLink(link))
+			}
+			*snapshot = &song
+		}
+	}
+	return entry
+}
@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/memory"
+	"song-library/pkg/repository/repositorytest"
+	"testing"
+)
+
+func TestMemorySongContract(t *testing.T) {
+	repositorytest.RunSongContract(t, func(t *testing.T) repository.Repositories {
+		return memory.NewRepositories(memory.NewStore())
+	})
+}
+
+func TestMemoryAuditContract(t *testing.T) {
+	repositorytest.RunAuditContract(t, func(t *testing.T) (repository.Repositories, repository.UnitOfWork) {
+		store := memory.NewStore()
+		return memory.NewRepositories(store), memory.NewUnitOfWork(store)
+	})
+}
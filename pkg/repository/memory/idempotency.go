@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"time"
+)
+
+type idempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) repository.IdempotencyRepository {
+	return &idempotencyRepository{store: store}
+}
+
+func (r *idempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := idempotencyKey{client: record.Client, key: record.Key}
+	if existing, ok := r.store.idempotency[key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return copyIdempotencyRecord(existing), nil
+	}
+	r.store.idempotency[key] = *copyIdempotencyRecord(*record)
+	return nil, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := idempotencyKey{client: record.Client, key: record.Key}
+	stored, ok := r.store.idempotency[key]
+	if !ok {
+		return nil
+	}
+	stored.Status = record.Status
+	stored.Headers = record.Headers
+	stored.Body = record.Body
+	r.store.idempotency[key] = *copyIdempotencyRecord(stored)
+	return nil
+}
+
+func (r *idempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, client, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotency, idempotencyKey{client: client, key: key})
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for key, record := range r.store.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(r.store.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type idempotencyKey struct {
+	client string
+	key    string
+}
+
+func copyIdempotencyRecord(record models.IdempotencyRecord) *models.IdempotencyRecord {
+	if record.Headers != nil {
+		headers := make(map[string]string, len(record.Headers))
+		for name, value := range record.Headers {
+			headers[name] = value
+		}
+		record.Headers = headers
+	}
+	record.Body = append([]byte(nil), record.Body...)
+	return &record
+}
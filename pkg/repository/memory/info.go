@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"time"
+)
+
+type songDetailRepository struct {
+	store *Store
+}
+
+func NewSongDetailRepository(store *Store) repository.SongDetailRepository {
+	return &songDetailRepository{store: store}
+}
+
+func (r *songDetailRepository) GetInfoByGroup(ctx context.Context, group string) ([]models.SongDetail, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var songDetails []models.SongDetail
+	for _, detail := range r.store.songDetails {
+		if !detail.DeletedAt.Valid && detail.Group == group {
+			songDetails = append(songDetails, detail)
+		}
+	}
+	return songDetails, nil
+}
+
+func (r *songDetailRepository) GetInfoBySong(ctx context.Context, song string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, detail := range r.store.songDetails {
+		if !detail.DeletedAt.Valid && detail.Song == song {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *songDetailRepository) GetSongDetail(ctx context.Context, group, song string) (models.SongDetail, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if i := r.store.songDetailIndex(group, song); i >= 0 {
+		return r.store.songDetails[i], nil
+	}
+	return models.SongDetail{}, utils.ErrSongNotFound
+}
+
+func (r *songDetailRepository) SongDetailExists(ctx context.Context, group, song string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.songDetailIndex(group, song) >= 0, nil
+}
+
+func (r *songDetailRepository) AddSongDetail(ctx context.Context, songDetail *models.SongDetail) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.songDetailIndex(songDetail.Group, songDetail.Song) >= 0 {
+		return utils.ErrSongAlreadyExists
+	}
+	r.store.songDetails = append(r.store.songDetails, *songDetail)
+	return nil
+}
+
+func (r *songDetailRepository) UpdateSongDetail(ctx context.Context, group, song string, songDetail *models.SongDetail) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if (songDetail.Group != group || songDetail.Song != song) && r.store.songDetailIndex(songDetail.Group, songDetail.Song) >= 0 {
+		return utils.ErrSongAlreadyExists
+	}
+	updated := false
+	for i, detail := range r.store.songDetails {
+		if !detail.DeletedAt.Valid && detail.Group == group && detail.Song == song {
+			r.store.songDetails[i] = *songDetail
+			updated = true
+		}
+	}
+	if !updated {
+		return utils.ErrSongNotFound
+	}
+	return nil
+}
+
+func (r *songDetailRepository) DeleteSongDetail(ctx context.Context, group, song string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted := false
+	now := time.Now()
+	for i, detail := range r.store.songDetails {
+		if !detail.DeletedAt.Valid && detail.Group == group && detail.Song == song {
+			r.store.songDetails[i].DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			deleted = true
+		}
+	}
+	if !deleted {
+		return utils.ErrSongNotFound
+	}
+	return nil
+}
+
+func (r *songDetailRepository) UpsertSongDetails(ctx context.Context, songDetails []models.SongDetail) (created, updated int, err error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, songDetail := range songDetails {
+		if i := r.store.songDetailIndex(songDetail.Group, songDetail.Song); i >= 0 {
+			r.store.songDetails[i] = songDetail
+			updated++
+			continue
+		}
+		r.store.songDetails = append(r.store.songDetails, songDetail)
+		created++
+	}
+	return created, updated, nil
+}
+
+// songDetailIndex returns the position of the active song detail or -1. The caller must
+// hold the lock.
+func (s *Store) songDetailIndex(group, song string) int {
+	for i, detail := range s.songDetails {
+		if !detail.DeletedAt.Valid && detail.Group == group && detail.Song == song {
+			return i
+		}
+	}
+	return -1
+}
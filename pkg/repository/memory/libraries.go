@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sort"
+	"time"
+)
+
+type libraryRepository struct {
+	store *Store
+}
+
+func NewLibraryRepository(store *Store) repository.LibraryRepository {
+	return &libraryRepository{store: store}
+}
+
+func (r *libraryRepository) GetLibraries(ctx context.Context) ([]models.Library, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	libraries := make([]models.Library, 0, len(r.store.libraries))
+	for _, library := range r.store.libraries {
+		libraries = append(libraries, library)
+	}
+	sort.Slice(libraries, func(i, j int) bool { return libraries[i].ID < libraries[j].ID })
+	return libraries, nil
+}
+
+func (r *libraryRepository) GetLibraryByID(ctx context.Context, id uint) (*models.Library, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	library, ok := r.store.libraries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &library, nil
+}
+
+func (r *libraryRepository) GetLibraryBySlug(ctx context.Context, slug string) (*models.Library, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, library := range r.store.libraries {
+		if library.Slug == slug {
+			return &library, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *libraryRepository) AddLibrary(ctx context.Context, library *models.Library) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.libraries {
+		if existing.Slug == library.Slug {
+			return utils.ErrLibraryAlreadyExists
+		}
+	}
+	r.store.lastLibraryID++
+	library.ID = r.store.lastLibraryID
+	now := time.Now()
+	library.CreatedAt, library.UpdatedAt = now, now
+	r.store.libraries[library.ID] = *library
+	return nil
+}
+
+// inLibrary reports whether the song is visible to queries in ctx.
+func inLibrary(ctx context.Context, song models.Song) bool {
+	libraryID := models.LibraryFromContext(ctx)
+	return libraryID == 0 || song.LibraryID == libraryID
+}
+
+// libraryOf returns the library a song added in ctx belongs to.
+func libraryOf(ctx context.Context) uint {
+	if libraryID := models.LibraryFromContext(ctx); libraryID != 0 {
+		return libraryID
+	}
+	return models.DefaultLibraryID
+}
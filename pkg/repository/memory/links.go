@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sort"
+	"time"
+)
+
+type linkRepository struct {
+	store *Store
+}
+
+func NewLinkRepository(store *Store) repository.LinkRepository {
+	return &linkRepository{store: store}
+}
+
+func (r *linkRepository) GetLinksBySongID(ctx context.Context, songID uint) ([]models.SongLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.songLinks(songID), nil
+}
+
+func (r *linkRepository) GetLinkByID(ctx context.Context, songID, linkID uint) (*models.SongLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	link, ok := r.store.links[linkID]
+	if !ok || link.SongID != songID {
+		return nil, utils.ErrLinkNotFound
+	}
+	return copyLink(link), nil
+}
+
+func (r *linkRepository) LinkExists(ctx context.Context, songID uint, url string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.linkExists(songID, url), nil
+}
+
+func (r *linkRepository) AddLink(ctx context.Context, link *models.SongLink) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.linkExists(link.SongID, link.URL) {
+		return utils.ErrLinkAlreadyExists
+	}
+
+	r.store.lastLinkID++
+	link.ID = r.store.lastLinkID
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	r.store.links[link.ID] = *copyLink(*link)
+	return nil
+}
+
+func (r *linkRepository) DeleteLink(ctx context.Context, songID, linkID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.links[linkID]
+	if !ok || link.SongID != songID {
+		return utils.ErrLinkNotFound
+	}
+	delete(r.store.links, linkID)
+	return nil
+}
+
+func (r *linkRepository) DeleteLinksBySongID(ctx context.Context, songID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, link := range r.store.links {
+		if link.SongID == songID {
+			delete(r.store.links, id)
+		}
+	}
+	return nil
+}
+
+func (r *linkRepository) GetLinksAfterID(ctx context.Context, afterID uint, limit int) ([]models.SongLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var links []models.SongLink
+	for _, link := range r.store.sortedLinks() {
+		if link.ID <= afterID || !r.store.songActive(link.SongID) {
+			continue
+		}
+		links = append(links, *copyLink(link))
+		if len(links) == limit {
+			break
+		}
+	}
+	return links, nil
+}
+
+func (r *linkRepository) UpdateLinkHealth(ctx context.Context, link *models.SongLink) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.links[link.ID]
+	if !ok {
+		return nil
+	}
+	existing.StatusCode = link.StatusCode
+	existing.LastError = link.LastError
+	existing.FailureStreak = link.FailureStreak
+	existing.LastCheckedAt = nil
+	if link.LastCheckedAt != nil {
+		checkedAt := *link.LastCheckedAt
+		existing.LastCheckedAt = &checkedAt
+	}
+	r.store.links[link.ID] = existing
+	return nil
+}
+
+func (r *linkRepository) GetBrokenLinks(ctx context.Context, minFailures, page, limit int) ([]models.BrokenLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	broken := []models.BrokenLink{}
+	for _, link := range r.store.sortedLinks() {
+		song, ok := r.store.songs[link.SongID]
+		if !ok || song.DeletedAt.Valid || !inLibrary(ctx, song) || link.FailureStreak < minFailures {
+			continue
+		}
+		broken = append(broken, models.BrokenLink{SongLink: *copyLink(link), Group: song.Group, Song: song.Song})
+	}
+	sort.SliceStable(broken, func(i, j int) bool { return broken[i].FailureStreak > broken[j].FailureStreak })
+
+	start, end, ok := paginate(len(broken), page, limit)
+	if !ok {
+		return []models.BrokenLink{}, nil
+	}
+	return broken[start:end], nil
+}
+
+// songLinks returns copies of the links of a song ordered by ID. The caller must hold the lock.
+func (s *Store) songLinks(songID uint) []models.SongLink {
+	links := []models.SongLink{}
+	for _, link := range s.sortedLinks() {
+		if link.SongID == songID {
+			links = append(links, *copyLink(link))
+		}
+	}
+	return links
+}
+
+func (s *Store) sortedLinks() []models.SongLink {
+	links := make([]models.SongLink, 0, len(s.links))
+	for _, link := range s.links {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+	return links
+}
+
+func (s *Store) linkExists(songID uint, url string) bool {
+	for _, link := range s.links {
+		if link.SongID == songID && link.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) songActive(id uint) bool {
+	song, ok := s.songs[id]
+	return ok && !song.DeletedAt.Valid
+}
+
+func copyLink(link models.SongLink) *models.SongLink {
+	if link.LastCheckedAt != nil {
+		checkedAt := *link.LastCheckedAt
+		link.LastCheckedAt = &checkedAt
+	}
+	return &link
+}
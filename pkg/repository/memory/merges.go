@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"sort"
+	"time"
+)
+
+type mergeRepository struct {
+	store *Store
+}
+
+func NewMergeRepository(store *Store) repository.MergeRepository {
+	return &mergeRepository{store: store}
+}
+
+func (r *mergeRepository) GetRedirect(ctx context.Context, fromID uint) (*models.SongRedirect, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	redirect, ok := r.store.redirects[fromID]
+	if !ok {
+		return nil, nil
+	}
+	if target, ok := r.store.songs[redirect.ToID]; !ok || target.DeletedAt.Valid || !inLibrary(ctx, target) {
+		return nil, nil
+	}
+	return &redirect, nil
+}
+
+func (r *mergeRepository) SaveRedirects(ctx context.Context, toID uint, fromIDs []uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	isSource := make(map[uint]bool, len(fromIDs))
+	for _, fromID := range fromIDs {
+		isSource[fromID] = true
+	}
+	for id, redirect := range r.store.redirects {
+		if isSource[redirect.ToID] {
+			redirect.ToID = toID
+			r.store.redirects[id] = redirect
+		}
+	}
+
+	now := time.Now()
+	for _, fromID := range fromIDs {
+		r.store.redirects[fromID] = models.SongRedirect{FromID: fromID, ToID: toID, CreatedAt: now}
+	}
+	return nil
+}
+
+func (r *mergeRepository) DeleteRedirectsBySongID(ctx context.Context, songID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, redirect := range r.store.redirects {
+		if redirect.FromID == songID || redirect.ToID == songID {
+			delete(r.store.redirects, id)
+		}
+	}
+	return nil
+}
+
+func (r *mergeRepository) AddMerge(ctx context.Context, merge *models.SongMerge) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastMergeID++
+	merge.ID = r.store.lastMergeID
+	if merge.CreatedAt.IsZero() {
+		merge.CreatedAt = time.Now()
+	}
+	r.store.merges[merge.ID] = copyMerge(*merge)
+	return nil
+}
+
+func (r *mergeRepository) GetMergesBySongID(ctx context.Context, songID uint) ([]models.SongMerge, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	merges := []models.SongMerge{}
+	for _, merge := range r.store.merges {
+		if merge.TargetID == songID {
+			merges = append(merges, copyMerge(merge))
+		}
+	}
+	sort.Slice(merges, func(i, j int) bool { return merges[i].ID < merges[j].ID })
+	return merges, nil
+}
+
+// copyMerge returns a merge that shares no slice or map with the given one.
+func copyMerge(merge models.SongMerge) models.SongMerge {
+	merge.SourceIDs = append([]uint(nil), merge.SourceIDs...)
+	fields := make(map[string]uint, len(merge.Fields))
+	for field, songID := range merge.Fields {
+		fields[field] = songID
+	}
+	merge.Fields = fields
+	return merge
+}
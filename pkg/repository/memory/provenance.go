@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"sort"
+)
+
+type provenanceRepository struct {
+	store *Store
+}
+
+func NewProvenanceRepository(store *Store) repository.ProvenanceRepository {
+	return &provenanceRepository{store: store}
+}
+
+func (r *provenanceRepository) SaveProvenance(ctx context.Context, records []models.SongFieldProvenance) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range records {
+		record := &records[i]
+		for id, existing := range r.store.provenance {
+			if existing.SongID == record.SongID && existing.Field == record.Field {
+				record.ID = id
+				break
+			}
+		}
+		if record.ID == 0 {
+			r.store.lastProvenanceID++
+			record.ID = r.store.lastProvenanceID
+		}
+		r.store.provenance[record.ID] = *record
+	}
+	return nil
+}
+
+func (r *provenanceRepository) GetProvenanceBySongID(ctx context.Context, songID uint) ([]models.SongFieldProvenance, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	records := []models.SongFieldProvenance{}
+	for _, record := range r.store.provenance {
+		if record.SongID == songID {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Field < records[j].Field })
+	return records, nil
+}
+
+func (r *provenanceRepository) DeleteProvenanceBySongID(ctx context.Context, songID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, record := range r.store.provenance {
+		if record.SongID == songID {
+			delete(r.store.provenance, id)
+		}
+	}
+	return nil
+}
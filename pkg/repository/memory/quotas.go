@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"time"
+)
+
+type quotaRepository struct {
+	store *Store
+}
+
+func NewQuotaRepository(store *Store) repository.QuotaRepository {
+	return &quotaRepository{store: store}
+}
+
+func (r *quotaRepository) IncrementQuotaUsage(ctx context.Context, client, class, day string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := quotaKey{client: client, class: class, day: day}
+	usage, ok := r.store.quotaUsages[key]
+	if !ok {
+		usage = models.QuotaUsage{Client: client, Class: class, Day: day}
+	}
+	usage.Requests++
+	usage.UpdatedAt = time.Now()
+	r.store.quotaUsages[key] = usage
+	return usage.Requests, nil
+}
+
+type quotaKey struct {
+	client string
+	class  string
+	day    string
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+type songRepository struct {
+	store *Store
+}
+
+func NewSongRepository(store *Store) repository.SongRepository {
+	return &songRepository{store: store}
+}
+
+func (r *songRepository) GetSongs(ctx context.Context, group, song string, includeDeleted bool, page, limit int) ([]models.Song, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matches []models.Song
+	for _, s := range r.store.sortedSongs() {
+		if (s.DeletedAt.Valid && !includeDeleted) || !inLibrary(ctx, s) || (group != "" && s.Group != group) || (song != "" && s.Song != song) {
+			continue
+		}
+		matches = append(matches, s)
+	}
+
+	start, end, ok := paginate(len(matches), page, limit)
+	if !ok {
+		return nil, nil
+	}
+
+	songs := make([]models.Song, 0, end-start)
+	for _, s := range matches[start:end] {
+		songs = append(songs, r.store.withLinks(s))
+	}
+	return songs, nil
+}
+
+func (r *songRepository) GetSongByID(ctx context.Context, id uint, includeDeleted bool) (*models.Song, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	s, ok := r.store.songs[id]
+	if !ok || (s.DeletedAt.Valid && !includeDeleted) || !inLibrary(ctx, s) {
+		return nil, nil
+	}
+	song := r.store.withLinks(s)
+	return &song, nil
+}
+
+func (r *songRepository) UpdateSong(ctx context.Context, song *models.Song) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.songs[song.ID]
+	if !ok || existing.DeletedAt.Valid || !inLibrary(ctx, existing) {
+		return nil
+	}
+	song.LibraryID = existing.LibraryID
+	song.SetKeys()
+	if r.store.songKeyTaken(*song) {
+		return utils.ErrSongAlreadyExists
+	}
+
+	existing.Group = song.Group
+	existing.Song = song.Song
+	existing.GroupKey = song.GroupKey
+	existing.SongKey = song.SongKey
+	existing.ReleaseDate = song.ReleaseDate
+	existing.Text = song.Text
+	existing.Link = song.Link
+	existing.Language = song.Language
+	existing.UpdatedBy = song.UpdatedBy
+	existing.UpdatedAt = time.Now()
+	song.UpdatedAt = existing.UpdatedAt
+
+	r.store.songs[song.ID] = existing
+	return nil
+}
+
+func (r *songRepository) SetSongLink(ctx context.Context, id uint, link string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.songs[id]; ok && !existing.DeletedAt.Valid && inLibrary(ctx, existing) {
+		existing.Link = link
+		existing.UpdatedAt = time.Now()
+		r.store.songs[id] = existing
+	}
+	return nil
+}
+
+func (r *songRepository) AddSong(ctx context.Context, song *models.Song) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if song.LibraryID == 0 {
+		song.LibraryID = libraryOf(ctx)
+	}
+	song.SetKeys()
+	if r.store.songKeyTaken(*song) {
+		return utils.ErrSongAlreadyExists
+	}
+	if song.ID == 0 {
+		r.store.lastSongID++
+		song.ID = r.store.lastSongID
+	} else if _, ok := r.store.songs[song.ID]; ok {
+		return utils.ErrSongAlreadyExists
+	} else if song.ID > r.store.lastSongID {
+		r.store.lastSongID = song.ID
+	}
+
+	now := time.Now()
+	if song.CreatedAt.IsZero() {
+		song.CreatedAt = now
+	}
+	if song.UpdatedAt.IsZero() {
+		song.UpdatedAt = now
+	}
+
+	stored := *song
+	stored.Links = nil
+	r.store.songs[song.ID] = stored
+	return nil
+}
+
+func (r *songRepository) GetLyrics(ctx context.Context, songName string, page, limit int) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, s := range r.store.sortedSongs() {
+		if !s.DeletedAt.Valid && inLibrary(ctx, s) && s.Song == songName {
+			return verses(s.Text, page, limit), nil
+		}
+	}
+	return nil, utils.ErrSongNotFound
+}
+
+func (r *songRepository) GetLyricsByText(ctx context.Context, searchText string, page, limit int) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, s := range r.store.sortedSongs() {
+		if !s.DeletedAt.Valid && inLibrary(ctx, s) && strings.Contains(s.Text, searchText) {
+			return verses(s.Text, page, limit), nil
+		}
+	}
+	return nil, utils.ErrSongNotFound
+}
+
+func (r *songRepository) SoftDeleteSong(ctx context.Context, id uint, deletedBy string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.songs[id]
+	if !ok || existing.DeletedAt.Valid || !inLibrary(ctx, existing) {
+		return utils.ErrSongNotFound
+	}
+
+	existing.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	existing.UpdatedBy = deletedBy
+	r.store.songs[id] = existing
+	return nil
+}
+
+func (r *songRepository) RestoreSong(ctx context.Context, id uint, restoredBy string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.songs[id]
+	if !ok || !existing.DeletedAt.Valid || !inLibrary(ctx, existing) {
+		return utils.ErrSongNotFound
+	}
+	if r.store.songKeyTaken(existing) {
+		return utils.ErrSongAlreadyExists
+	}
+
+	existing.DeletedAt = gorm.DeletedAt{}
+	existing.UpdatedAt = time.Now()
+	existing.UpdatedBy = restoredBy
+	r.store.songs[id] = existing
+	return nil
+}
+
+func (r *songRepository) HardDeleteSong(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.songs[id]; ok && inLibrary(ctx, existing) {
+		delete(r.store.songs, id)
+	}
+	return nil
+}
+
+func (r *songRepository) SongExists(ctx context.Context, group, song string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	candidate := models.Song{LibraryID: libraryOf(ctx), Group: group, Song: song}
+	candidate.SetKeys()
+	return r.store.songKeyTaken(candidate), nil
+}
+
+func (r *songRepository) GetSharedGroups(ctx context.Context) ([]models.SongGroup, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	type groupKey struct {
+		libraryID uint
+		groupKey  string
+	}
+	var groups []models.SongGroup
+	counts := make(map[groupKey]int)
+	// Songs are sorted by ID, so the first song seen of a group has its lowest ID.
+	for _, s := range r.store.sortedSongs() {
+		if s.DeletedAt.Valid || !inLibrary(ctx, s) {
+			continue
+		}
+		key := groupKey{libraryID: s.LibraryID, groupKey: s.GroupKey}
+		counts[key]++
+		if counts[key] == 1 {
+			groups = append(groups, models.SongGroup{LibraryID: s.LibraryID, GroupKey: s.GroupKey, FirstSongID: s.ID})
+		}
+	}
+
+	shared := []models.SongGroup{}
+	for _, group := range groups {
+		if counts[groupKey{libraryID: group.LibraryID, groupKey: group.GroupKey}] > 1 {
+			shared = append(shared, group)
+		}
+	}
+	return shared, nil
+}
+
+func (r *songRepository) GetGroupSongs(ctx context.Context, group models.SongGroup) ([]models.Song, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var songs []models.Song
+	for _, s := range r.store.sortedSongs() {
+		if !s.DeletedAt.Valid && s.LibraryID == group.LibraryID && s.GroupKey == group.GroupKey {
+			songs = append(songs, s)
+		}
+	}
+	return songs, nil
+}
+
+// songKeyTaken reports whether another active song of the same library has the same
+// normalised group and title, mirroring the partial unique index of the SQL backends. The
+// caller must hold the lock.
+func (s *Store) songKeyTaken(song models.Song) bool {
+	if song.GroupKey == "" {
+		return false
+	}
+	for _, existing := range s.songs {
+		if existing.ID != song.ID && !existing.DeletedAt.Valid && existing.LibraryID == song.LibraryID &&
+			existing.GroupKey == song.GroupKey && existing.SongKey == song.SongKey {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSongs returns all songs ordered by ID. The caller must hold the lock.
+func (s *Store) sortedSongs() []models.Song {
+	songs := make([]models.Song, 0, len(s.songs))
+	for _, song := range s.songs {
+		songs = append(songs, song)
+	}
+	sort.Slice(songs, func(i, j int) bool { return songs[i].ID < songs[j].ID })
+	return songs
+}
+
+// withLinks returns a copy of the song with its links attached. The caller must hold the lock.
+func (s *Store) withLinks(song models.Song) models.Song {
+	song.Links = s.songLinks(song.ID)
+	return song
+}
+
+func verses(text string, page, limit int) []string {
+	all := strings.Split(text, "\n\n")
+	start, end, ok := paginate(len(all), page, limit)
+	if !ok {
+		return nil
+	}
+	return all[start:end]
+}
@@ -0,0 +1,104 @@
+// Package memory implements the repository interfaces on top of plain maps guarded by a
+// single lock. It needs no infrastructure and is meant for demos and integration tests.
+package memory
+
+import (
+	"song-library/models"
+	"song-library/pkg/repository"
+	"sync"
+	"time"
+)
+
+// Store holds all data of the in-memory backend. Repositories created from the same Store
+// see each other's writes, the same way the GORM repositories share one database.
+type Store struct {
+	mu sync.RWMutex
+
+	songs       map[uint]models.Song
+	songDetails []models.SongDetail
+	links       map[uint]models.SongLink
+	provenance  map[uint]models.SongFieldProvenance
+	redirects   map[uint]models.SongRedirect
+	merges      map[uint]models.SongMerge
+	apiKeys     map[uint]models.APIKey
+	users       map[uint]models.User
+	libraries   map[uint]models.Library
+	quotaUsages map[quotaKey]models.QuotaUsage
+	audit       []models.AuditEntry
+	idempotency map[idempotencyKey]models.IdempotencyRecord
+
+	refreshTokens map[uint]models.RefreshToken
+
+	lastSongID       uint
+	lastLinkID       uint
+	lastProvenanceID uint
+	lastMergeID      uint
+	lastAPIKeyID     uint
+	lastUserID       uint
+	lastLibraryID    uint
+
+	lastRefreshTokenID uint
+}
+
+// NewStore returns an empty store holding only the default library.
+func NewStore() *Store {
+	s := newStore()
+	now := time.Now()
+	s.libraries[models.DefaultLibraryID] = models.Library{
+		ID:        models.DefaultLibraryID,
+		Slug:      "default",
+		Name:      "Default",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.lastLibraryID = models.DefaultLibraryID
+	return s
+}
+
+func newStore() *Store {
+	return &Store{
+		songs:      make(map[uint]models.Song),
+		links:      make(map[uint]models.SongLink),
+		provenance: make(map[uint]models.SongFieldProvenance),
+		redirects:  make(map[uint]models.SongRedirect),
+		merges:     make(map[uint]models.SongMerge),
+		apiKeys:    make(map[uint]models.APIKey),
+		users:      make(map[uint]models.User),
+		libraries:  make(map[uint]models.Library),
+
+		quotaUsages: make(map[quotaKey]models.QuotaUsage),
+		idempotency: make(map[idempotencyKey]models.IdempotencyRecord),
+
+		refreshTokens: make(map[uint]models.RefreshToken),
+	}
+}
+
+func NewRepositories(store *Store) repository.Repositories {
+	return repository.Repositories{
+		Songs:       NewSongRepository(store),
+		SongDetails: NewSongDetailRepository(store),
+		Links:       NewLinkRepository(store),
+		Provenance:  NewProvenanceRepository(store),
+		Merges:      NewMergeRepository(store),
+		APIKeys:     NewAPIKeyRepository(store),
+		Users:       NewUserRepository(store),
+		Libraries:   NewLibraryRepository(store),
+		Quotas:      NewQuotaRepository(store),
+		Audit:       NewAuditRepository(store),
+		Idempotency: NewIdempotencyRepository(store),
+	}
+}
+
+// paginate returns the bounds of the requested page of a list with n elements, or ok=false
+// if the page starts past the end of the list.
+func paginate(n, page, limit int) (start, end int, ok bool) {
+	start = (page - 1) * limit
+	if start < 0 || start >= n {
+		return 0, 0, false
+	}
+	end = start + limit
+	if end > n {
+		end = n
+	}
+	return start, end, true
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"song-library/pkg/repository"
+)
+
+type unitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) repository.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+// Do runs fn on a copy of the store while holding the store's write lock, so a unit of work
+// is serialised with every other access, and keeps the copy only if fn succeeds.
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	tx := u.store.clone()
+	if err := fn(NewRepositories(tx)); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.store.songs, u.store.songDetails = tx.songs, tx.songDetails
+	u.store.links, u.store.provenance = tx.links, tx.provenance
+	u.store.redirects, u.store.merges = tx.redirects, tx.merges
+	u.store.lastSongID, u.store.lastLinkID = tx.lastSongID, tx.lastLinkID
+	u.store.lastProvenanceID, u.store.lastMergeID = tx.lastProvenanceID, tx.lastMergeID
+	u.store.apiKeys, u.store.lastAPIKeyID = tx.apiKeys, tx.lastAPIKeyID
+	u.store.users, u.store.lastUserID = tx.users, tx.lastUserID
+	u.store.refreshTokens, u.store.lastRefreshTokenID = tx.refreshTokens, tx.lastRefreshTokenID
+	u.store.libraries, u.store.lastLibraryID = tx.libraries, tx.lastLibraryID
+	u.store.quotaUsages, u.store.audit = tx.quotaUsages, tx.audit
+	u.store.idempotency = tx.idempotency
+	return nil
+}
+
+// clone returns a deep copy of the store's data. The caller must hold the lock.
+func (s *Store) clone() *Store {
+	c := newStore()
+	for id, song := range s.songs {
+		c.songs[id] = song
+	}
+	c.songDetails = append(c.songDetails, s.songDetails...)
+	for id, link := range s.links {
+		c.links[id] = *copyLink(link)
+	}
+	for id, record := range s.provenance {
+		c.provenance[id] = record
+	}
+	for id, redirect := range s.redirects {
+		c.redirects[id] = redirect
+	}
+	for id, merge := range s.merges {
+		c.merges[id] = copyMerge(merge)
+	}
+	for id, key := range s.apiKeys {
+		c.apiKeys[id] = copyAPIKey(key)
+	}
+	c.lastSongID, c.lastLinkID = s.lastSongID, s.lastLinkID
+	c.lastProvenanceID, c.lastMergeID = s.lastProvenanceID, s.lastMergeID
+	for id, user := range s.users {
+		c.users[id] = copyUser(user)
+	}
+	for id, token := range s.refreshTokens {
+		c.refreshTokens[id] = *copyRefreshToken(token)
+	}
+	c.lastAPIKeyID, c.lastUserID = s.lastAPIKeyID, s.lastUserID
+	c.lastRefreshTokenID = s.lastRefreshTokenID
+	for id, library := range s.libraries {
+		c.libraries[id] = library
+	}
+	c.lastLibraryID = s.lastLibraryID
+	for key, usage := range s.quotaUsages {
+		c.quotaUsages[key] = usage
+	}
+	for key, record := range s.idempotency {
+		c.idempotency[key] = *copyIdempotencyRecord(record)
+	}
+	// Entries are never changed once appended, so the copy may share them.
+	c.audit = s.audit[:len(s.audit):len(s.audit)]
+	return c
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sort"
+	"time"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) GetUsers(ctx context.Context) ([]models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		users = append(users, copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, nil
+	}
+	user = copyUser(user)
+	return &user, nil
+}
+
+func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username {
+			user = copyUser(user)
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userRepository) AddUser(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.users {
+		if existing.Username == user.Username {
+			return utils.ErrUserAlreadyExists
+		}
+	}
+	r.store.lastUserID++
+	user.ID = r.store.lastUserID
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.store.users[user.ID] = copyUser(*user)
+	return nil
+}
+
+func (r *userRepository) UpdateUserRole(ctx context.Context, id uint, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return utils.ErrUserNotFound
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *userRepository) AddRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastRefreshTokenID++
+	token.ID = r.store.lastRefreshTokenID
+	token.CreatedAt = time.Now()
+	r.store.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *userRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return copyRefreshToken(token), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userRepository) RevokeRefreshToken(ctx context.Context, id uint, revokedAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.RevokedAt = &revokedAt
+	r.store.refreshTokens[id] = token
+	return true, nil
+}
+
+func (r *userRepository) RevokeRefreshTokensByUserID(ctx context.Context, userID uint, revokedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			revoked := revokedAt
+			token.RevokedAt = &revoked
+			r.store.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
+
+func copyUser(user models.User) models.User {
+	if user.LibraryID != nil {
+		libraryID := *user.LibraryID
+		user.LibraryID = &libraryID
+	}
+	return user
+}
+
+func copyRefreshToken(token models.RefreshToken) *models.RefreshToken {
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		token.RevokedAt = &revokedAt
+	}
+	return &token
+}
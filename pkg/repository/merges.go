@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type mergeRepository struct {
+	db *gorm.DB
+}
+
+func NewMergeRepository(db *gorm.DB) MergeRepository {
+	return &mergeRepository{db: db}
+}
+
+func (r *mergeRepository) GetRedirect(ctx context.Context, fromID uint) (*models.SongRedirect, error) {
+	var redirect models.SongRedirect
+	err := r.db.WithContext(ctx).Model(&models.SongRedirect{}).
+		Select("song_redirects.*").
+		Joins("JOIN songs ON songs.id = song_redirects.to_id AND songs.deleted_at IS NULL").
+		Scopes(inLibrary(ctx)).
+		Where("song_redirects.from_id = ?", fromID).
+		Take(&redirect).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error.Printf("[repository.GetRedirect]: Error finding redirect: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &redirect, nil
+}
+
+func (r *mergeRepository) SaveRedirects(ctx context.Context, toID uint, fromIDs []uint) error {
+	if len(fromIDs) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SongRedirect{}).Where("to_id IN ?", fromIDs).Update("to_id", toID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		redirects := make([]models.SongRedirect, 0, len(fromIDs))
+		for _, fromID := range fromIDs {
+			redirects = append(redirects, models.SongRedirect{FromID: fromID, ToID: toID, CreatedAt: now})
+		}
+		return tx.Create(&redirects).Error
+	})
+	if err != nil {
+		logger.Error.Printf("[repository.SaveRedirects]: Error saving redirects: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *mergeRepository) DeleteRedirectsBySongID(ctx context.Context, songID uint) error {
+	if err := r.db.WithContext(ctx).Where("from_id = ? OR to_id = ?", songID, songID).Delete(&models.SongRedirect{}).Error; err != nil {
+		logger.Error.Printf("[repository.DeleteRedirectsBySongID]: Error deleting redirects: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *mergeRepository) AddMerge(ctx context.Context, merge *models.SongMerge) error {
+	if err := r.db.WithContext(ctx).Create(merge).Error; err != nil {
+		logger.Error.Printf("[repository.AddMerge]: Error adding merge: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *mergeRepository) GetMergesBySongID(ctx context.Context, songID uint) ([]models.SongMerge, error) {
+	merges := []models.SongMerge{}
+	if err := r.db.WithContext(ctx).Where("target_id = ?", songID).Order("id").Find(&merges).Error; err != nil {
+		logger.Error.Printf("[repository.GetMergesBySongID]: Error finding merges: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return merges, nil
+}
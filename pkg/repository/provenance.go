@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+)
+
+type provenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewProvenanceRepository(db *gorm.DB) ProvenanceRepository {
+	return &provenanceRepository{db: db}
+}
+
+func (r *provenanceRepository) SaveProvenance(ctx context.Context, records []models.SongFieldProvenance) error {
+	if len(records) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "song_id"}, {Name: "field"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "response_hash", "recorded_at"}),
+	}).Create(&records).Error
+	if err != nil {
+		logger.Error.Printf("[repository.SaveProvenance]: Error saving provenance: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *provenanceRepository) GetProvenanceBySongID(ctx context.Context, songID uint) ([]models.SongFieldProvenance, error) {
+	var records []models.SongFieldProvenance
+	err := r.db.WithContext(ctx).Where("song_id = ?", songID).Order("field").Find(&records).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetProvenanceBySongID]: Error finding provenance: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return records, nil
+}
+
+func (r *provenanceRepository) DeleteProvenanceBySongID(ctx context.Context, songID uint) error {
+	err := r.db.WithContext(ctx).Where("song_id = ?", songID).Delete(&models.SongFieldProvenance{}).Error
+	if err != nil {
+		logger.Error.Printf("[repository.DeleteProvenanceBySongID]: Error deleting provenance: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
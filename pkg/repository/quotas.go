@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// IncrementQuotaUsage counts one more request in a single upsert, so concurrent requests
+// of a client never lose a count.
+func (r *quotaRepository) IncrementQuotaUsage(ctx context.Context, client, class, day string) (int, error) {
+	usage := models.QuotaUsage{Client: client, Class: class, Day: day, Requests: 1, UpdatedAt: time.Now()}
+	err := r.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "client"}, {Name: "class"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"requests":   gorm.Expr("quota_usages.requests + 1"),
+				"updated_at": usage.UpdatedAt,
+			}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "requests"}}},
+	).Create(&usage).Error
+	if err != nil {
+		logger.Error.Printf("[repository.IncrementQuotaUsage]: Error counting request: %s\n", err.Error())
+		return 0, utils.ErrDatabaseConnectionFailed
+	}
+	return usage.Requests, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"song-library/models"
+	"time"
+)
+
+// SongRepository only sees the songs of the library in the context of each call (see
+// models.ContextWithLibrary), or those of every library if the context names none.
+type SongRepository interface {
+	// GetSongs and GetSongByID leave soft-deleted songs out unless includeDeleted is set;
+	// all other methods only see active songs. GetSongs pages through the songs by ID.
+	GetSongs(ctx context.Context, group, song string, includeDeleted bool, page, limit int) ([]models.Song, error)
+	GetSongByID(ctx context.Context, id uint, includeDeleted bool) (*models.Song, error)
+	// UpdateSong overwrites the group, title, release date, text, link and language of the
+	// song.
+	UpdateSong(ctx context.Context, song *models.Song) error
+	SetSongLink(ctx context.Context, id uint, link string) error
+	AddSong(ctx context.Context, song *models.Song) error
+	GetLyrics(ctx context.Context, songName string, page, limit int) ([]string, error)
+	GetLyricsByText(ctx context.Context, searchText string, page, limit int) ([]string, error)
+	// SoftDeleteSong marks the song as deleted by deletedBy.
+	SoftDeleteSong(ctx context.Context, id uint, deletedBy string) error
+	// RestoreSong undoes the soft delete of the song on behalf of restoredBy. It fails with
+	// ErrSongAlreadyExists if an active song has taken its group and title since.
+	RestoreSong(ctx context.Context, id uint, restoredBy string) error
+	HardDeleteSong(ctx context.Context, id uint) error
+	// SongExists reports whether the library of ctx holds an active song with the group
+	// and title.
+	SongExists(ctx context.Context, group, song string) (bool, error)
+	// GetSharedGroups returns the groups with more than one active song, ordered by the
+	// lowest song ID in each.
+	GetSharedGroups(ctx context.Context) ([]models.SongGroup, error)
+	// GetGroupSongs returns the active songs of the group ordered by ID, without their links.
+	GetGroupSongs(ctx context.Context, group models.SongGroup) ([]models.Song, error)
+}
+
+type SongDetailRepository interface {
+	GetInfoByGroup(ctx context.Context, group string) ([]models.SongDetail, error)
+	GetInfoBySong(ctx context.Context, song string) (bool, error)
+	GetSongDetail(ctx context.Context, group, song string) (models.SongDetail, error)
+	SongDetailExists(ctx context.Context, group, song string) (bool, error)
+	AddSongDetail(ctx context.Context, songDetail *models.SongDetail) error
+	UpdateSongDetail(ctx context.Context, group, song string, songDetail *models.SongDetail) error
+	DeleteSongDetail(ctx context.Context, group, song string) error
+	UpsertSongDetails(ctx context.Context, songDetails []models.SongDetail) (created, updated int, err error)
+}
+
+type ProvenanceRepository interface {
+	SaveProvenance(ctx context.Context, records []models.SongFieldProvenance) error
+	GetProvenanceBySongID(ctx context.Context, songID uint) ([]models.SongFieldProvenance, error)
+	DeleteProvenanceBySongID(ctx context.Context, songID uint) error
+}
+
+type LinkRepository interface {
+	GetLinksBySongID(ctx context.Context, songID uint) ([]models.SongLink, error)
+	GetLinkByID(ctx context.Context, songID, linkID uint) (*models.SongLink, error)
+	LinkExists(ctx context.Context, songID uint, url string) (bool, error)
+	AddLink(ctx context.Context, link *models.SongLink) error
+	DeleteLink(ctx context.Context, songID, linkID uint) error
+	DeleteLinksBySongID(ctx context.Context, songID uint) error
+	GetLinksAfterID(ctx context.Context, afterID uint, limit int) ([]models.SongLink, error)
+	UpdateLinkHealth(ctx context.Context, link *models.SongLink) error
+	// GetBrokenLinks only returns links of songs in the library of ctx, if it names one.
+	GetBrokenLinks(ctx context.Context, minFailures, page, limit int) ([]models.BrokenLink, error)
+}
+
+type MergeRepository interface {
+	// GetRedirect only returns the redirect if the song it points to is active and in the
+	// library of ctx, if it names one, so it never reveals songs of other libraries.
+	GetRedirect(ctx context.Context, fromID uint) (*models.SongRedirect, error)
+	// SaveRedirects points the given songs, and every song already redirected to one of
+	// them, to toID.
+	SaveRedirects(ctx context.Context, toID uint, fromIDs []uint) error
+	DeleteRedirectsBySongID(ctx context.Context, songID uint) error
+	AddMerge(ctx context.Context, merge *models.SongMerge) error
+	GetMergesBySongID(ctx context.Context, songID uint) ([]models.SongMerge, error)
+}
+
+type APIKeyRepository interface {
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	AddAPIKey(ctx context.Context, key *models.APIKey) error
+	// UpdateAPIKey saves the prefix, hash, expiry and revocation time of the key.
+	UpdateAPIKey(ctx context.Context, key *models.APIKey) error
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type UserRepository interface {
+	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	AddUser(ctx context.Context, user *models.User) error
+	UpdateUserRole(ctx context.Context, id uint, role string) error
+	AddRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// RevokeRefreshToken revokes the token and reports whether it was still active, so of
+	// two requests using the same token only one succeeds.
+	RevokeRefreshToken(ctx context.Context, id uint, revokedAt time.Time) (bool, error)
+	RevokeRefreshTokensByUserID(ctx context.Context, userID uint, revokedAt time.Time) error
+}
+
+type LibraryRepository interface {
+	GetLibraries(ctx context.Context) ([]models.Library, error)
+	GetLibraryByID(ctx context.Context, id uint) (*models.Library, error)
+	GetLibraryBySlug(ctx context.Context, slug string) (*models.Library, error)
+	AddLibrary(ctx context.Context, library *models.Library) error
+}
+
+type QuotaRepository interface {
+	// IncrementQuotaUsage counts a request of the client in the route class on the given
+	// UTC day and returns the number of requests counted so far.
+	IncrementQuotaUsage(ctx context.Context, client, class, day string) (int, error)
+}
+
+// AuditRepository is append-only: entries are never changed or removed.
+type AuditRepository interface {
+	// AddAuditEntry stamps the entry, seals it onto the end of the chain and stores it.
+	// Concurrent calls are serialised, so the chain never forks; a call in a transaction
+	// that would have to link to an entry it cannot see fails instead.
+	AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	// GetAuditEntries returns the entries matching the filter, newest first.
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+	// GetAuditEntriesAfterID returns up to limit entries following afterID in chain order.
+	GetAuditEntriesAfterID(ctx context.Context, afterID uint, limit int) ([]models.AuditEntry, error)
+}
+
+// IdempotencyRepository keeps the first response to requests sent with an Idempotency-Key,
+// per client and key.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores the record, which has no response yet, for the request
+	// about to be handled. If the client already holds an unexpired record for the key it
+	// is returned instead and nothing is stored.
+	ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// CompleteIdempotencyKey saves the status, headers and body of the response.
+	CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error
+	// ReleaseIdempotencyKey removes the record, so the key can be used again.
+	ReleaseIdempotencyKey(ctx context.Context, client, key string) error
+	// DeleteExpiredIdempotencyRecords removes the records expired by now and returns how
+	// many there were.
+	DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error)
+}
+
+// UnitOfWork runs several repository calls atomically. The repositories handed to fn share
+// one transaction, which is committed when fn returns nil and rolled back otherwise. fn must
+// only use the repositories it is given.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// Repositories bundles the repositories of one storage backend.
+type Repositories struct {
+	Songs       SongRepository
+	SongDetails SongDetailRepository
+	Links       LinkRepository
+	Provenance  ProvenanceRepository
+	Merges      MergeRepository
+	APIKeys     APIKeyRepository
+	Users       UserRepository
+	Libraries   LibraryRepository
+	Quotas      QuotaRepository
+	Audit       AuditRepository
+	Idempotency IdempotencyRepository
+}
+
+// Router picks the connection of a query. Writes, and reads that must see them, use Writer;
+// queries that tolerate slightly stale data may use Reader.
+type Router interface {
+	Writer() *gorm.DB
+	Reader() *gorm.DB
+}
+
+// NewRepositories returns repositories running every query on db.
+func NewRepositories(db *gorm.DB) Repositories {
+	return NewRoutedRepositories(singleConn{db: db})
+}
+
+func NewRoutedRepositories(router Router) Repositories {
+	db := router.Writer()
+	return Repositories{
+		Songs:       NewSongRepository(db, router.Reader),
+		SongDetails: NewSongDetailRepository(db),
+		Links:       NewLinkRepository(db),
+		Provenance:  NewProvenanceRepository(db),
+		Merges:      NewMergeRepository(db),
+		APIKeys:     NewAPIKeyRepository(db),
+		Users:       NewUserRepository(db),
+		Libraries:   NewLibraryRepository(db),
+		Quotas:      NewQuotaRepository(db),
+		Audit:       NewAuditRepository(db),
+		Idempotency: NewIdempotencyRepository(db),
+	}
+}
+
+type singleConn struct {
+	db *gorm.DB
+}
+
+func (c singleConn) Writer() *gorm.DB {
+	return c.db
+}
+
+func (c singleConn) Reader() *gorm.DB {
+	return c.db
+}
@@ -0,0 +1,136 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"net/http"
+	"song-library/models"
+	"song-library/pkg/repository"
+	services "song-library/pkg/services"
+	"sync"
+	"testing"
+	"time"
+)
+
+// OpenWithUnitOfWork is like Open, and also returns a unit of work on the same database.
+type OpenWithUnitOfWork func(t *testing.T) (repository.Repositories, repository.UnitOfWork)
+
+// RunAuditContract checks the audit repository of the backend against the contract of
+// repository.AuditRepository: entries read back with the hash they were sealed with, and
+// however appends interleave, every entry links to the one stored before it.
+func RunAuditContract(t *testing.T, open OpenWithUnitOfWork) {
+	discardLogs()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		repos, _ := open(t)
+		testAuditRoundTrip(t, repos)
+	})
+	t.Run("ConcurrentAppends", func(t *testing.T) {
+		repos, uow := open(t)
+		testConcurrentAppends(t, repos, uow)
+	})
+}
+
+func testAuditRoundTrip(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	created := time.Date(2026, 3, 1, 9, 30, 15, 123456789, moscow)
+	songID := uint(7)
+	before := &models.Song{
+		ID: songID, LibraryID: 2, Group: "Ария", Song: "Штиль", ReleaseDate: "01.01.2001",
+		Text: "Paranoia is in bloom,\n\"the PR\" <transmissions> & \u2028 will resume", Link: "https://example.com/a?b=1&c=2",
+		Language: "ru", CreatedAt: created, UpdatedAt: created.Add(time.Hour), UpdatedBy: "editor",
+	}
+	after := *before
+	after.Text = ""
+	after.Links = []models.SongLink{{ID: 1, SongID: songID, Platform: models.PlatformGeneric, URL: "https://example.com/a"}}
+	after.DeletedAt = gorm.DeletedAt{Time: created.Add(2 * time.Hour).UTC(), Valid: true}
+
+	var stored []models.AuditEntry
+	for _, entry := range []*models.AuditEntry{
+		{Actor: "editor", ClientIP: "203.0.113.5", Method: http.MethodPut, Route: "/songs/:id", Path: "/songs/7", LibraryID: 2, SongID: &songID, Before: before, After: &after, Status: http.StatusOK},
+		{Actor: "editor", Method: http.MethodDelete, Route: "/songs/hard/:id", Path: "/songs/hard/7", SongID: &songID, Before: &after, Status: http.StatusOK},
+		{Method: http.MethodPost, Route: "/auth/login", Path: "/auth/login", Status: http.StatusUnauthorized, Error: "ErrInvalidCredentials"},
+	} {
+		if err := repos.Audit.AddAuditEntry(ctx, entry); err != nil {
+			t.Fatalf("AddAuditEntry: %v", err)
+		}
+		stored = append(stored, *entry)
+	}
+
+	entries, err := repos.Audit.GetAuditEntriesAfterID(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetAuditEntriesAfterID: %v", err)
+	}
+	if len(entries) != len(stored) {
+		t.Fatalf("GetAuditEntriesAfterID = %d entries, want %d", len(entries), len(stored))
+	}
+	prevHash := ""
+	for i := range entries {
+		if entries[i].Hash != stored[i].Hash || !entries[i].Intact(prevHash) {
+			t.Errorf("entry %d read back = %+v, want it intact with hash %s", i+1, entries[i], stored[i].Hash)
+		}
+		prevHash = entries[i].Hash
+	}
+	if got := entries[0].Before; got == nil || got.Text != before.Text || got.Group != before.Group || !got.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("Before read back = %+v, want %+v", got, before)
+	}
+	if got := entries[0].After; got == nil || !got.DeletedAt.Valid || len(got.Links) != 1 {
+		t.Errorf("After read back = %+v, want %+v", got, after)
+	}
+
+	result, err := services.NewAuditService(repos.Audit).VerifyAuditChain(ctx)
+	if err != nil || !result.Intact || result.Checked != len(stored) {
+		t.Errorf("VerifyAuditChain = %+v, %v, want %d intact entries", result, err, len(stored))
+	}
+}
+
+func testConcurrentAppends(t *testing.T, repos repository.Repositories, uow repository.UnitOfWork) {
+	ctx := context.Background()
+	const appends = 20
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		stored int
+	)
+	for i := 0; i < appends; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry := &models.AuditEntry{Actor: fmt.Sprintf("client-%d", i), Method: http.MethodPost, Route: "/songs/", Status: http.StatusCreated}
+
+			var err error
+			if i%2 == 0 {
+				err = repos.Audit.AddAuditEntry(ctx, entry)
+			} else {
+				// The read comes first, so the transaction has its snapshot before it appends.
+				err = uow.Do(ctx, func(repos repository.Repositories) error {
+					if _, err := repos.Audit.GetAuditEntriesAfterID(ctx, 0, 1); err != nil {
+						return err
+					}
+					return repos.Audit.AddAuditEntry(ctx, entry)
+				})
+			}
+			// An append in a transaction may fail rather than fork the chain; it is then
+			// rolled back and left out.
+			if err == nil {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			} else if i%2 == 0 {
+				t.Errorf("AddAuditEntry: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	result, err := services.NewAuditService(repos.Audit).VerifyAuditChain(ctx)
+	if err != nil {
+		t.Fatalf("VerifyAuditChain: %v", err)
+	}
+	if !result.Intact || result.Checked != stored {
+		t.Errorf("VerifyAuditChain = %+v, want an intact chain of the %d stored entries", result, stored)
+	}
+}
@@ -0,0 +1,383 @@
+// Package repositorytest holds the behaviour every storage backend must share. Each backend
+// runs RunSongContract against itself from its own tests.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"testing"
+)
+
+// Open returns the repositories of a new, empty database of one backend, holding only the
+// default library.
+type Open func(t *testing.T) repository.Repositories
+
+// RunSongContract checks the song repository of the backend against the contract of
+// repository.SongRepository: filters, pagination, soft delete, SongExists, lyrics search
+// and the per-library uniqueness of group and title, along with the library scoping of
+// merge redirects and the uniqueness of active song details.
+func RunSongContract(t *testing.T, open Open) {
+	discardLogs()
+
+	t.Run("Filters", func(t *testing.T) { testFilters(t, open(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, open(t)) })
+	t.Run("SoftDelete", func(t *testing.T) { testSoftDelete(t, open(t)) })
+	t.Run("SongExists", func(t *testing.T) { testSongExists(t, open(t)) })
+	t.Run("LyricsSearch", func(t *testing.T) { testLyricsSearch(t, open(t)) })
+	t.Run("UniquePerLibrary", func(t *testing.T) { testUniquePerLibrary(t, open(t)) })
+	t.Run("SharedGroups", func(t *testing.T) { testSharedGroups(t, open(t)) })
+	t.Run("Redirects", func(t *testing.T) { testRedirects(t, open(t)) })
+	t.Run("UniqueSongDetails", func(t *testing.T) { testUniqueSongDetails(t, open(t)) })
+}
+
+// discardLogs gives the package loggers somewhere to write, as logger.Init does for the
+// server.
+func discardLogs() {
+	for _, l := range []**log.Logger{&logger.Info, &logger.Error, &logger.Warning, &logger.Debug} {
+		if *l == nil {
+			*l = log.New(io.Discard, "", 0)
+		}
+	}
+}
+
+func testFilters(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	other := contextWithNewLibrary(t, repos, "other")
+	addSong(t, repos, ctx, "Muse", "Uprising", "")
+	addSong(t, repos, ctx, "Muse", "Hysteria", "")
+	addSong(t, repos, ctx, "Queen", "One", "")
+	addSong(t, repos, other, "Muse", "Madness", "")
+
+	for _, test := range []struct {
+		name        string
+		ctx         context.Context
+		group, song string
+		want        []string
+	}{
+		{"no filter", ctx, "", "", []string{"Uprising", "Hysteria", "One", "Madness"}},
+		{"group", ctx, "Muse", "", []string{"Uprising", "Hysteria", "Madness"}},
+		{"song", ctx, "", "One", []string{"One"}},
+		{"group and song", ctx, "Muse", "Hysteria", []string{"Hysteria"}},
+		{"no match", ctx, "Muse", "One", nil},
+		{"group compared as written", ctx, "muse", "", nil},
+		{"library", other, "Muse", "", []string{"Madness"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			songs, err := repos.Songs.GetSongs(test.ctx, test.group, test.song, false, 1, 10)
+			if err != nil {
+				t.Fatalf("GetSongs: %v", err)
+			}
+			if got := titles(songs); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetSongs(%q, %q) = %v, want %v", test.group, test.song, got, test.want)
+			}
+		})
+	}
+}
+
+func testPagination(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	for _, title := range []string{"S1", "S2", "S3", "S4", "S5"} {
+		addSong(t, repos, ctx, "Group", title, "")
+	}
+
+	for page, want := range [][]string{{"S1", "S2"}, {"S3", "S4"}, {"S5"}, nil} {
+		songs, err := repos.Songs.GetSongs(ctx, "Group", "", false, page+1, 2)
+		if err != nil {
+			t.Fatalf("GetSongs page %d: %v", page+1, err)
+		}
+		if got := titles(songs); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetSongs page %d = %v, want %v", page+1, got, want)
+		}
+	}
+
+	addSong(t, repos, ctx, "Group", "Verses", "a\n\nb\n\nc")
+	for page, want := range [][]string{{"a", "b"}, {"c"}, nil} {
+		verses, err := repos.Songs.GetLyrics(ctx, "Verses", page+1, 2)
+		if err != nil {
+			t.Fatalf("GetLyrics page %d: %v", page+1, err)
+		}
+		if len(verses) == 0 {
+			verses = nil
+		}
+		if !reflect.DeepEqual(verses, want) {
+			t.Errorf("GetLyrics page %d = %v, want %v", page+1, verses, want)
+		}
+	}
+}
+
+func testSoftDelete(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	deleted := addSong(t, repos, ctx, "Muse", "Uprising", "They will not force us")
+	addSong(t, repos, ctx, "Muse", "Hysteria", "It's bugging me")
+
+	if err := repos.Songs.SoftDeleteSong(ctx, deleted.ID, "tester"); err != nil {
+		t.Fatalf("SoftDeleteSong: %v", err)
+	}
+
+	songs, err := repos.Songs.GetSongs(ctx, "", "", false, 1, 10)
+	if err != nil {
+		t.Fatalf("GetSongs: %v", err)
+	}
+	if got := titles(songs); !reflect.DeepEqual(got, []string{"Hysteria"}) {
+		t.Errorf("GetSongs = %v, want the deleted song left out", got)
+	}
+	songs, err = repos.Songs.GetSongs(ctx, "", "", true, 1, 10)
+	if err != nil {
+		t.Fatalf("GetSongs including deleted: %v", err)
+	}
+	if got := titles(songs); !reflect.DeepEqual(got, []string{"Uprising", "Hysteria"}) {
+		t.Errorf("GetSongs including deleted = %v, want both songs", got)
+	}
+
+	if song, err := repos.Songs.GetSongByID(ctx, deleted.ID, false); err != nil || song != nil {
+		t.Errorf("GetSongByID = %v, %v, want no song", song, err)
+	}
+	song, err := repos.Songs.GetSongByID(ctx, deleted.ID, true)
+	if err != nil || song == nil {
+		t.Fatalf("GetSongByID including deleted = %v, %v, want the song", song, err)
+	}
+	if !song.DeletedAt.Valid || song.UpdatedBy != "tester" {
+		t.Errorf("deleted song has DeletedAt %v and UpdatedBy %q, want a time and %q", song.DeletedAt, song.UpdatedBy, "tester")
+	}
+
+	if _, err := repos.Songs.GetLyrics(ctx, "Uprising", 1, 10); !errors.Is(err, utils.ErrSongNotFound) {
+		t.Errorf("GetLyrics = %v, want ErrSongNotFound", err)
+	}
+	if _, err := repos.Songs.GetLyricsByText(ctx, "force", 1, 10); !errors.Is(err, utils.ErrSongNotFound) {
+		t.Errorf("GetLyricsByText = %v, want ErrSongNotFound", err)
+	}
+	if exists, err := repos.Songs.SongExists(ctx, "Muse", "Uprising"); err != nil || exists {
+		t.Errorf("SongExists = %v, %v, want false", exists, err)
+	}
+	if groups, err := repos.Songs.GetSharedGroups(ctx); err != nil || len(groups) != 0 {
+		t.Errorf("GetSharedGroups = %v, %v, want none", groups, err)
+	}
+	if err := repos.Songs.SoftDeleteSong(ctx, deleted.ID, "tester"); !errors.Is(err, utils.ErrSongNotFound) {
+		t.Errorf("SoftDeleteSong of a deleted song = %v, want ErrSongNotFound", err)
+	}
+
+	if err := repos.Songs.RestoreSong(ctx, deleted.ID, "restorer"); err != nil {
+		t.Fatalf("RestoreSong: %v", err)
+	}
+	if song, err := repos.Songs.GetSongByID(ctx, deleted.ID, false); err != nil || song == nil || song.UpdatedBy != "restorer" {
+		t.Errorf("GetSongByID after RestoreSong = %v, %v, want the song restored by %q", song, err, "restorer")
+	}
+	if err := repos.Songs.RestoreSong(ctx, deleted.ID, "restorer"); !errors.Is(err, utils.ErrSongNotFound) {
+		t.Errorf("RestoreSong of an active song = %v, want ErrSongNotFound", err)
+	}
+}
+
+func testSongExists(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	other := contextWithNewLibrary(t, repos, "other")
+	addSong(t, repos, ctx, "Sigur Rós", "Hoppípolla", "")
+
+	for _, test := range []struct {
+		name        string
+		ctx         context.Context
+		group, song string
+		want        bool
+	}{
+		{"as stored", ctx, "Sigur Rós", "Hoppípolla", true},
+		{"case and spacing", ctx, "  SIGUR   rós ", "HOPPÍPOLLA", true},
+		{"other title", ctx, "Sigur Rós", "Glósóli", false},
+		{"other group", ctx, "Sigur Ros", "Hoppípolla", false},
+		{"other library", other, "Sigur Rós", "Hoppípolla", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			exists, err := repos.Songs.SongExists(test.ctx, test.group, test.song)
+			if err != nil {
+				t.Fatalf("SongExists: %v", err)
+			}
+			if exists != test.want {
+				t.Errorf("SongExists(%q, %q) = %v, want %v", test.group, test.song, exists, test.want)
+			}
+		})
+	}
+}
+
+func testLyricsSearch(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	addSong(t, repos, ctx, "Group", "Greeting", "Hello World\n\nSecond verse")
+	addSong(t, repos, ctx, "Group", "Farewell", "Goodbye World")
+	addSong(t, repos, ctx, "Group", "Certain", "100% sure_thing")
+
+	for _, test := range []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{"substring", "llo Wor", []string{"Hello World", "Second verse"}},
+		{"first matching song", "World", []string{"Hello World", "Second verse"}},
+		{"later song", "Goodbye", []string{"Goodbye World"}},
+		{"case compared as written", "world", nil},
+		{"no match", "Farewell", nil},
+		{"percent sign taken literally", "0% s", []string{"100% sure_thing"}},
+		{"percent sign is no wildcard", "Hello%verse", nil},
+		{"underscore taken literally", "e_t", []string{"100% sure_thing"}},
+		{"underscore is no wildcard", "Hell_ World", nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			verses, err := repos.Songs.GetLyricsByText(ctx, test.search, 1, 10)
+			if test.want == nil {
+				if !errors.Is(err, utils.ErrSongNotFound) {
+					t.Errorf("GetLyricsByText(%q) = %v, %v, want ErrSongNotFound", test.search, verses, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetLyricsByText(%q): %v", test.search, err)
+			}
+			if !reflect.DeepEqual(verses, test.want) {
+				t.Errorf("GetLyricsByText(%q) = %v, want %v", test.search, verses, test.want)
+			}
+		})
+	}
+}
+
+func testUniquePerLibrary(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	other := contextWithNewLibrary(t, repos, "other")
+	original := addSong(t, repos, ctx, "Muse", "Uprising", "")
+	hysteria := addSong(t, repos, ctx, "Muse", "Hysteria", "")
+
+	if err := repos.Songs.AddSong(ctx, &models.Song{Group: " muse", Song: "UPRISING "}); !errors.Is(err, utils.ErrSongAlreadyExists) {
+		t.Errorf("AddSong of a taken group and title = %v, want ErrSongAlreadyExists", err)
+	}
+	addSong(t, repos, other, "Muse", "Uprising", "")
+
+	hysteria.Song = "uprising"
+	if err := repos.Songs.UpdateSong(ctx, hysteria); !errors.Is(err, utils.ErrSongAlreadyExists) {
+		t.Errorf("UpdateSong to a taken group and title = %v, want ErrSongAlreadyExists", err)
+	}
+
+	if err := repos.Songs.SoftDeleteSong(ctx, original.ID, "tester"); err != nil {
+		t.Fatalf("SoftDeleteSong: %v", err)
+	}
+	addSong(t, repos, ctx, "Muse", "Uprising", "")
+	if err := repos.Songs.RestoreSong(ctx, original.ID, "tester"); !errors.Is(err, utils.ErrSongAlreadyExists) {
+		t.Errorf("RestoreSong of a song whose group and title were taken = %v, want ErrSongAlreadyExists", err)
+	}
+}
+
+func testSharedGroups(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	other := contextWithNewLibrary(t, repos, "other")
+	queen := addSong(t, repos, ctx, "Queen", "One", "")
+	muse := addSong(t, repos, ctx, "Muse", "Uprising", "")
+	addSong(t, repos, ctx, "QUEEN", "Two", "")
+	addSong(t, repos, ctx, "muse", "Hysteria", "")
+	addSong(t, repos, ctx, "Blur", "Song 2", "")
+	addSong(t, repos, other, "Blur", "Beetlebum", "")
+	deleted := addSong(t, repos, ctx, "Muse", "Madness", "")
+	if err := repos.Songs.SoftDeleteSong(ctx, deleted.ID, "tester"); err != nil {
+		t.Fatalf("SoftDeleteSong: %v", err)
+	}
+
+	groups, err := repos.Songs.GetSharedGroups(ctx)
+	if err != nil {
+		t.Fatalf("GetSharedGroups: %v", err)
+	}
+	want := []models.SongGroup{
+		{LibraryID: models.DefaultLibraryID, GroupKey: "queen", FirstSongID: queen.ID},
+		{LibraryID: models.DefaultLibraryID, GroupKey: "muse", FirstSongID: muse.ID},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("GetSharedGroups = %+v, want %+v", groups, want)
+	}
+
+	songs, err := repos.Songs.GetGroupSongs(ctx, groups[1])
+	if err != nil {
+		t.Fatalf("GetGroupSongs: %v", err)
+	}
+	if got := titles(songs); !reflect.DeepEqual(got, []string{"Uprising", "Hysteria"}) {
+		t.Errorf("GetGroupSongs = %v, want the active songs of the group", got)
+	}
+}
+
+func testRedirects(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	other := contextWithNewLibrary(t, repos, "other")
+	defaultLibrary := models.ContextWithLibrary(ctx, models.DefaultLibraryID)
+	merged := addSong(t, repos, other, "Muse", "Uprising (Live)", "")
+	target := addSong(t, repos, other, "Muse", "Uprising", "")
+	if err := repos.Merges.SaveRedirects(ctx, target.ID, []uint{merged.ID}); err != nil {
+		t.Fatalf("SaveRedirects: %v", err)
+	}
+
+	for name, ctx := range map[string]context.Context{"any library": ctx, "its library": other} {
+		if redirect, err := repos.Merges.GetRedirect(ctx, merged.ID); err != nil || redirect == nil || redirect.ToID != target.ID {
+			t.Errorf("GetRedirect in %s = %+v, %v, want a redirect to song %d", name, redirect, err, target.ID)
+		}
+	}
+	if redirect, err := repos.Merges.GetRedirect(defaultLibrary, merged.ID); err != nil || redirect != nil {
+		t.Errorf("GetRedirect in another library = %+v, %v, want none", redirect, err)
+	}
+
+	if err := repos.Songs.SoftDeleteSong(ctx, target.ID, "tester"); err != nil {
+		t.Fatalf("SoftDeleteSong: %v", err)
+	}
+	if redirect, err := repos.Merges.GetRedirect(other, merged.ID); err != nil || redirect != nil {
+		t.Errorf("GetRedirect to a deleted song = %+v, %v, want none", redirect, err)
+	}
+}
+
+func testUniqueSongDetails(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	for _, detail := range []*models.SongDetail{{Group: "Muse", Song: "Uprising"}, {Group: "Muse", Song: "Hysteria"}} {
+		if err := repos.SongDetails.AddSongDetail(ctx, detail); err != nil {
+			t.Fatalf("AddSongDetail(%q): %v", detail.Song, err)
+		}
+	}
+
+	if err := repos.SongDetails.AddSongDetail(ctx, &models.SongDetail{Group: "Muse", Song: "Uprising"}); !errors.Is(err, utils.ErrSongAlreadyExists) {
+		t.Errorf("AddSongDetail of a taken group and title = %v, want ErrSongAlreadyExists", err)
+	}
+	if err := repos.SongDetails.UpdateSongDetail(ctx, "Muse", "Hysteria", &models.SongDetail{Group: "Muse", Song: "Uprising"}); !errors.Is(err, utils.ErrSongAlreadyExists) {
+		t.Errorf("UpdateSongDetail to a taken group and title = %v, want ErrSongAlreadyExists", err)
+	}
+
+	if err := repos.SongDetails.DeleteSongDetail(ctx, "Muse", "Uprising"); err != nil {
+		t.Fatalf("DeleteSongDetail: %v", err)
+	}
+	if err := repos.SongDetails.AddSongDetail(ctx, &models.SongDetail{Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009"}); err != nil {
+		t.Errorf("AddSongDetail of a deleted group and title = %v, want it added", err)
+	}
+	if detail, err := repos.SongDetails.GetSongDetail(ctx, "Muse", "Uprising"); err != nil || detail.ReleaseDate != "07.09.2009" {
+		t.Errorf("GetSongDetail = %+v, %v, want the new song detail", detail, err)
+	}
+}
+
+func addSong(t *testing.T, repos repository.Repositories, ctx context.Context, group, title, text string) *models.Song {
+	t.Helper()
+	song := &models.Song{Group: group, Song: title, Text: text}
+	if err := repos.Songs.AddSong(ctx, song); err != nil {
+		t.Fatalf("AddSong(%q, %q): %v", group, title, err)
+	}
+	return song
+}
+
+// contextWithNewLibrary adds a library and returns a context limited to it.
+func contextWithNewLibrary(t *testing.T, repos repository.Repositories, slug string) context.Context {
+	t.Helper()
+	library := &models.Library{Slug: slug, Name: slug}
+	if err := repos.Libraries.AddLibrary(context.Background(), library); err != nil {
+		t.Fatalf("AddLibrary(%q): %v", slug, err)
+	}
+	return models.ContextWithLibrary(context.Background(), library.ID)
+}
+
+func titles(songs []models.Song) []string {
+	var titles []string
+	for _, song := range songs {
+		titles = append(titles, song.Song)
+	}
+	return titles
+}
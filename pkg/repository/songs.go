@@ -1,9 +1,10 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"gorm.io/gorm"
-	"song-library/db"
+	"gorm.io/gorm/clause"
 	"song-library/logger"
 	"song-library/models"
 	"song-library/utils"
@@ -11,19 +12,30 @@ import (
 	"time"
 )
 
-func GetSongs(group, song string, page, limit int) ([]models.Song, error) {
+type songRepository struct {
+	db     *gorm.DB
+	reader func() *gorm.DB
+}
+
+// NewSongRepository runs listing, search and lyrics queries on the connections handed out
+// by reader, which may lag behind db, and everything else on db.
+func NewSongRepository(db *gorm.DB, reader func() *gorm.DB) SongRepository {
+	return &songRepository{db: db, reader: reader}
+}
+
+func (r *songRepository) GetSongs(ctx context.Context, group, song string, includeDeleted bool, page, limit int) ([]models.Song, error) {
 	var songs []models.Song
 	offset := (page - 1) * limit
 
-	query := db.GetDBConn().Model(&songs).Where("deleted_at IS NULL")
+	query := scoped(r.reader().WithContext(ctx), includeDeleted).Model(&songs).Scopes(inLibrary(ctx))
 	if group != "" {
-		query = query.Where("\"group\" = ?", group)
+		query = query.Where(groupIs(group))
 	}
 	if song != "" {
 		query = query.Where("song = ?", song)
 	}
 
-	err := query.Offset(offset).Limit(limit).Find(&songs).Error
+	err := query.Preload("Links", orderLinks).Order("id").Offset(offset).Limit(limit).Find(&songs).Error
 	if err != nil {
 		logger.Error.Printf("[repository.GetSongs]: Error finding songs: %s\n", err.Error())
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -38,9 +50,9 @@ func GetSongs(group, song string, page, limit int) ([]models.Song, error) {
 	return songs, nil
 }
 
-func GetSongByID(id uint) (*models.Song, error) {
+func (r *songRepository) GetSongByID(ctx context.Context, id uint, includeDeleted bool) (*models.Song, error) {
 	var song models.Song
-	err := db.GetDBConn().Where("id = ? AND deleted_at IS NULL", id).First(&song).Error
+	err := scoped(r.db.WithContext(ctx), includeDeleted).Scopes(inLibrary(ctx)).Preload("Links", orderLinks).Where("id = ?", id).First(&song).Error
 	if err != nil {
 		logger.Error.Printf("[repository.GetSongByID]: Error finding song: %s\n", err.Error())
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -51,25 +63,61 @@ func GetSongByID(id uint) (*models.Song, error) {
 	return &song, nil
 }
 
-func UpdateSong(song *models.Song) error {
-	if err := db.GetDBConn().Model(song).Updates(song).Error; err != nil {
+func (r *songRepository) UpdateSong(ctx context.Context, song *models.Song) error {
+	song.SetKeys()
+	err := r.db.WithContext(ctx).Model(song).Scopes(inLibrary(ctx)).
+		Select("group", "song", "group_key", "song_key", "release_date", "text", "link", "language", "updated_at", "updated_by").
+		Omit(clause.Associations).
+		Updates(song).Error
+	if err != nil {
 		logger.Error.Printf("[repository.UpdateSong]: Error updating song: %s\n", err.Error())
-		return err
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return utils.ErrSongAlreadyExists
+		}
+		return utils.ErrDatabaseConnectionFailed
 	}
 	return nil
 }
 
-func AddSong(song *models.Song) error {
-	if err := db.GetDBConn().Create(song).Error; err != nil {
+// AddSong stores the song in the library of ctx unless it names its own.
+func (r *songRepository) AddSong(ctx context.Context, song *models.Song) error {
+	if song.LibraryID == 0 {
+		song.LibraryID = libraryOf(ctx)
+	}
+	song.SetKeys()
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(song).Error; err != nil {
 		logger.Error.Printf("[repository.AddSong]: Error adding song: %s\n", err.Error())
-		return err
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return utils.ErrSongAlreadyExists
+		}
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *songRepository) SetSongLink(ctx context.Context, id uint, link string) error {
+	if err := r.db.WithContext(ctx).Model(&models.Song{}).Scopes(inLibrary(ctx)).Where("id = ?", id).Update("link", link).Error; err != nil {
+		logger.Error.Printf("[repository.SetSongLink]: Error updating song link: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
 	}
 	return nil
 }
 
-func GetLyrics(songName string, page, limit int) (verses []string, err error) {
+// scoped leaves soft-deleted rows out of the query unless includeDeleted is set.
+func scoped(db *gorm.DB, includeDeleted bool) *gorm.DB {
+	if includeDeleted {
+		return db.Unscoped()
+	}
+	return db
+}
+
+func orderLinks(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func (r *songRepository) GetLyrics(ctx context.Context, songName string, page, limit int) (verses []string, err error) {
 	var song models.Song
-	err = db.GetDBConn().Where("song = ? AND deleted_at IS NULL", songName).First(&song).Error
+	err = r.reader().WithContext(ctx).Scopes(inLibrary(ctx)).Where("song = ?", songName).First(&song).Error
 	if err != nil {
 		logger.Error.Printf("[repository.GetLyrics]: Error finding song: %s\n", err.Error())
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -89,9 +137,10 @@ func GetLyrics(songName string, page, limit int) (verses []string, err error) {
 	return verses[start:end], nil
 }
 
-func GetLyricsByText(searchText string, page, limit int) ([]string, error) {
+func (r *songRepository) GetLyricsByText(ctx context.Context, searchText string, page, limit int) ([]string, error) {
 	var songs []models.Song
-	err := db.GetDBConn().Where("text LIKE ? AND deleted_at IS NULL", "%"+searchText+"%").Find(&songs).Error
+	reader := r.reader()
+	err := reader.WithContext(ctx).Scopes(inLibrary(ctx)).Where(containsText(reader, "text", searchText)).Order("id").Find(&songs).Error
 	if err != nil {
 		logger.Error.Printf("[repository.GetLyricsByText]: Error finding songs: %s\n", err.Error())
 		return nil, utils.ErrDatabaseConnectionFailed
@@ -116,28 +165,38 @@ func GetLyricsByText(searchText string, page, limit int) ([]string, error) {
 	return verses[start:end], nil
 }
 
-func SoftDeleteSong(id uint) (err error) {
-	var song models.Song
-	if err := db.GetDBConn().First(&song, id).Error; err != nil {
-		logger.Error.Printf("[repository.SoftDeleteSong]: Error finding song: %s\n", err.Error())
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return utils.ErrSongNotFound
-		}
+func (r *songRepository) SoftDeleteSong(ctx context.Context, id uint, deletedBy string) (err error) {
+	result := r.db.WithContext(ctx).Model(&models.Song{}).Scopes(inLibrary(ctx)).Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "updated_by": deletedBy})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.SoftDeleteSong]: Error deleting song: %s\n", result.Error.Error())
 		return utils.ErrDatabaseConnectionFailed
 	}
+	if result.RowsAffected == 0 {
+		return utils.ErrSongNotFound
+	}
+	return nil
+}
 
-	currentTime := time.Now()
-	song.DeletedAt = &currentTime
-
-	if err := db.GetDBConn().Save(&song).Error; err != nil {
+func (r *songRepository) RestoreSong(ctx context.Context, id uint, restoredBy string) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Song{}).Scopes(inLibrary(ctx)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "updated_by": restoredBy})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.RestoreSong]: Error restoring song: %s\n", result.Error.Error())
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return utils.ErrSongAlreadyExists
+		}
 		return utils.ErrDatabaseConnectionFailed
 	}
-
+	if result.RowsAffected == 0 {
+		return utils.ErrSongNotFound
+	}
 	return nil
 }
 
-func HardDeleteSong(id uint) (err error) {
-	if err = db.GetDBConn().Unscoped().Where("id = ?", id).Delete(&models.Song{}).Error; err != nil {
+func (r *songRepository) HardDeleteSong(ctx context.Context, id uint) (err error) {
+	if err = r.db.WithContext(ctx).Unscoped().Scopes(inLibrary(ctx)).Where("id = ?", id).Delete(&models.Song{}).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Error.Printf("[repository.HardDeleteSong]: Error finding song: %s\n", err.Error())
 			return utils.ErrSongNotFound
@@ -147,13 +206,42 @@ func HardDeleteSong(id uint) (err error) {
 	return nil
 }
 
-func SongExists(group, song string) (bool, error) {
+// SongExists reports whether an active song with the same normalised group and title exists
+// in the library of ctx.
+func (r *songRepository) SongExists(ctx context.Context, group, song string) (bool, error) {
 	var count int64
-	if err := db.GetDBConn().Model(&models.Song{}).
-		Where("\"group\" = ? AND song = ?", group, song).
+	if err := r.db.WithContext(ctx).Model(&models.Song{}).Where("songs.library_id = ?", libraryOf(ctx)).
+		Where("group_key = ? AND song_key = ?", models.NormalizeKey(group), models.NormalizeKey(song)).
 		Count(&count).Error; err != nil {
 		logger.Error.Printf("[repository.SongExists]: Error checking if song exists: %s\n", err.Error())
-		return false, err
+		return false, utils.ErrDatabaseConnectionFailed
 	}
 	return count > 0, nil
 }
+
+func (r *songRepository) GetSharedGroups(ctx context.Context) ([]models.SongGroup, error) {
+	groups := []models.SongGroup{}
+	err := r.db.WithContext(ctx).Model(&models.Song{}).Scopes(inLibrary(ctx)).
+		Select("library_id, group_key, MIN(id) AS first_song_id").
+		Group("library_id, group_key").
+		Having("COUNT(*) > 1").
+		Order("first_song_id").
+		Scan(&groups).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetSharedGroups]: Error finding groups: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return groups, nil
+}
+
+func (r *songRepository) GetGroupSongs(ctx context.Context, group models.SongGroup) ([]models.Song, error) {
+	var songs []models.Song
+	err := r.db.WithContext(ctx).
+		Where("library_id = ? AND group_key = ?", group.LibraryID, group.GroupKey).
+		Order("id").Find(&songs).Error
+	if err != nil {
+		logger.Error.Printf("[repository.GetGroupSongs]: Error finding songs: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return songs, nil
+}
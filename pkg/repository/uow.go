@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+)
+
+type unitOfWork struct {
+	db        *gorm.DB
+	isolation sql.IsolationLevel
+}
+
+// NewUnitOfWork returns a unit of work running its transactions on db with the given
+// isolation level. SQLite ignores the level, its transactions are always serializable.
+func NewUnitOfWork(db *gorm.DB, isolation sql.IsolationLevel) UnitOfWork {
+	return &unitOfWork{db: db, isolation: isolation}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	}, &sql.TxOptions{Isolation: u.isolation})
+}
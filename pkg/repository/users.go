@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/utils"
+	"time"
+)
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) GetUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		logger.Error.Printf("[repository.GetUsers]: Error finding users: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return users, nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.findUser(ctx, "id = ?", id)
+}
+
+func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findUser(ctx, "username = ?", username)
+}
+
+func (r *userRepository) findUser(ctx context.Context, query string, arg interface{}) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error.Printf("[repository.findUser]: Error finding user: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &user, nil
+}
+
+func (r *userRepository) AddUser(ctx context.Context, user *models.User) error {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return utils.ErrUserAlreadyExists
+		}
+		logger.Error.Printf("[repository.AddUser]: Error adding user: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateUserRole(ctx context.Context, id uint, role string) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"role": role, "updated_at": time.Now()})
+	if result.Error != nil {
+		logger.Error.Printf("[repository.UpdateUserRole]: Error updating user: %s\n", result.Error.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) AddRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		logger.Error.Printf("[repository.AddRefreshToken]: Error adding refresh token: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
+
+func (r *userRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error.Printf("[repository.GetRefreshTokenByHash]: Error finding refresh token: %s\n", err.Error())
+		return nil, utils.ErrDatabaseConnectionFailed
+	}
+	return &token, nil
+}
+
+func (r *userRepository) RevokeRefreshToken(ctx context.Context, id uint, revokedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		logger.Error.Printf("[repository.RevokeRefreshToken]: Error revoking refresh token: %s\n", result.Error.Error())
+		return false, utils.ErrDatabaseConnectionFailed
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userRepository) RevokeRefreshTokensByUserID(ctx context.Context, userID uint, revokedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		logger.Error.Printf("[repository.RevokeRefreshTokensByUserID]: Error revoking refresh tokens: %s\n", err.Error())
+		return utils.ErrDatabaseConnectionFailed
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefixLength is the number of leading characters of a key kept in the clear.
+	apiKeyPrefixLength = 8
+	// apiKeyTouchInterval limits how often the last use of a key is written back.
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyService struct {
+	keys      repository.APIKeyRepository
+	libraries repository.LibraryRepository
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepository, libraries repository.LibraryRepository) *APIKeyService {
+	return &APIKeyService{keys: keys, libraries: libraries}
+}
+
+func (s *APIKeyService) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.keys.GetAPIKeys(ctx)
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, request models.NewAPIKeyRequest) (*models.IssuedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, utils.ErrInvalidAPIKeyRequest
+	}
+	scopes, err := validateScopes(request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, utils.ErrInvalidAPIKeyRequest
+	}
+	if err := validateLibraryBinding(ctx, s.libraries, request.LibraryID, scopes, utils.ErrInvalidAPIKeyRequest); err != nil {
+		return nil, err
+	}
+
+	plain, err := generateSecret("sl_")
+	if err != nil {
+		logger.Error.Printf("[services.CreateAPIKey]: Error generating key: %v", err)
+		return nil, utils.ErrUnexpectedError
+	}
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    plain[:apiKeyPrefixLength],
+		KeyHash:   hashSecret(plain),
+		Scopes:    scopes,
+		LibraryID: request.LibraryID,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := s.keys.AddAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{APIKey: *key, Key: plain}, nil
+}
+
+// EnsureAPIKey stores the given plain key with the given scopes unless it is already
+// known. It lets an operator bootstrap the first admin key.
+func (s *APIKeyService) EnsureAPIKey(ctx context.Context, name, plain string, scopes []string) error {
+	if len(plain) < apiKeyPrefixLength {
+		return utils.ErrInvalidAPIKeyRequest
+	}
+
+	existing, err := s.keys.GetAPIKeyByHash(ctx, hashSecret(plain))
+	if err != nil || existing != nil {
+		return err
+	}
+	return s.keys.AddAPIKey(ctx, &models.APIKey{
+		Name:    name,
+		Prefix:  plain[:apiKeyPrefixLength],
+		KeyHash: hashSecret(plain),
+		Scopes:  scopes,
+	})
+}
+
+// RotateAPIKey replaces the secret of an active key; the old one stops working at once.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id uint) (*models.IssuedAPIKey, error) {
+	key, err := s.findActiveAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := generateSecret("sl_")
+	if err != nil {
+		logger.Error.Printf("[services.RotateAPIKey]: Error generating key: %v", err)
+		return nil, utils.ErrUnexpectedError
+	}
+	key.Prefix = plain[:apiKeyPrefixLength]
+	key.KeyHash = hashSecret(plain)
+	key.UpdatedAt = time.Now()
+	if err := s.keys.UpdateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{APIKey: *key, Key: plain}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uint) error {
+	key, err := s.findActiveAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	key.UpdatedAt = now
+	return s.keys.UpdateAPIKey(ctx, key)
+}
+
+// Authenticate returns the active key matching the plain key, or ErrUnauthorized.
+func (s *APIKeyService) Authenticate(ctx context.Context, plain string) (*models.APIKey, error) {
+	if plain == "" {
+		return nil, utils.ErrUnauthorized
+	}
+
+	key, err := s.keys.GetAPIKeyByHash(ctx, hashSecret(plain))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if key == nil || !key.Active(now) {
+		return nil, utils.ErrUnauthorized
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keys.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logger.Error.Printf("[services.Authenticate]: Error recording use of key %d: %v", key.ID, err)
+		}
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) findActiveAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	key, err := s.keys.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, utils.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// hashSecret returns the hex SHA-256 hash under which an API key or refresh token is stored.
+func hashSecret(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateSecret returns 32 random bytes, hex encoded behind the given prefix.
+func generateSecret(prefix string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(secret), nil
+}
+
+func validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, utils.ErrInvalidAPIKeyRequest
+	}
+
+	known := make(map[string]bool, len(models.Scopes))
+	for _, scope := range models.Scopes {
+		known[scope] = true
+	}
+	seen := make(map[string]bool, len(scopes))
+	var valid []string
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, utils.ErrInvalidAPIKeyRequest
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
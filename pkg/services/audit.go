@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+)
+
+// auditVerifyBatch is how many entries VerifyAuditChain reads at a time.
+const auditVerifyBatch = 500
+
+type AuditService struct {
+	audit repository.AuditRepository
+}
+
+func NewAuditService(audit repository.AuditRepository) *AuditService {
+	return &AuditService{audit: audit}
+}
+
+// Record appends the entry to the audit log.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditEntry) error {
+	return s.audit.AddAuditEntry(ctx, entry)
+}
+
+// GetAuditEntries lists the entries matching the filter, newest first.
+func (s *AuditService) GetAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Page <= 0 || filter.Limit <= 0 || filter.Limit > 100 {
+		logger.Error.Printf("[services.GetAuditEntries]: page %d or limit %d", filter.Page, filter.Limit)
+		return nil, utils.ErrInvalidPaginationParams
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, utils.ErrInvalidRequestParameter
+	}
+	return s.audit.GetAuditEntries(ctx, filter)
+}
+
+// VerifyAuditChain walks the audit log from the first entry and checks that each entry
+// still matches its hash and links to the one before it. It stops at the first entry that
+// does not.
+func (s *AuditService) VerifyAuditChain(ctx context.Context) (*models.AuditVerification, error) {
+	result := &models.AuditVerification{Intact: true}
+	var afterID uint
+	for {
+		entries, err := s.audit.GetAuditEntriesAfterID(ctx, afterID, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entry := &entries[i]
+			if !entry.Intact(result.LastHash) {
+				logger.Error.Printf("[services.VerifyAuditChain]: Audit entry %d does not match its hash chain", entry.ID)
+				result.Intact = false
+				result.BrokenAt = &entry.ID
+				return result, nil
+			}
+			result.Checked++
+			result.LastHash = entry.Hash
+			afterID = entry.ID
+		}
+		if len(entries) < auditVerifyBatch {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"song-library/models"
+	"song-library/pkg/repository"
+	services "song-library/pkg/services"
+	"testing"
+	"time"
+)
+
+// auditLog is an audit repository over entries kept in chain order, which tests can
+// tamper with as someone with access to the database could.
+type auditLog struct {
+	repository.AuditRepository
+	entries []models.AuditEntry
+}
+
+func (l *auditLog) GetAuditEntriesAfterID(ctx context.Context, afterID uint, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	for _, entry := range l.entries {
+		if entry.ID > afterID && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// newAuditLog returns a log of n sealed entries with the IDs 1 to n. There are more than
+// VerifyAuditChain reads at a time, so the chain is followed across batches.
+func newAuditLog(n int) *auditLog {
+	log := &auditLog{}
+	prevHash := ""
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		songID := uint(i)
+		entry := models.AuditEntry{
+			ID:        uint(i),
+			CreatedAt: created.Add(time.Duration(i) * time.Second),
+			Actor:     fmt.Sprintf("client-%d", i%3),
+			Method:    "PUT",
+			Route:     "/songs/:id",
+			Path:      fmt.Sprintf("/songs/%d", i),
+			SongID:    &songID,
+			After:     &models.Song{ID: songID, Group: "Muse", Song: fmt.Sprintf("Song %d", i)},
+			Status:    200,
+		}
+		entry.Seal(prevHash)
+		prevHash = entry.Hash
+		log.entries = append(log.entries, entry)
+	}
+	return log
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	discardLogs()
+	const entries = 1200
+
+	for _, test := range []struct {
+		name     string
+		tamper   func(log *auditLog)
+		brokenAt uint
+		checked  int
+	}{
+		{"intact", func(log *auditLog) {}, 0, entries},
+		{"edited field", func(log *auditLog) { log.entries[699].Status = 500 }, 700, 699},
+		{"edited song", func(log *auditLog) { log.entries[9].After.Song = "Hysteria" }, 10, 9},
+		{"resealed entry", func(log *auditLog) {
+			log.entries[499].Actor = "someone else"
+			log.entries[499].Seal(log.entries[498].Hash)
+		}, 501, 500},
+		{"deleted entry", func(log *auditLog) {
+			log.entries = append(log.entries[:500:500], log.entries[501:]...)
+		}, 502, 500},
+		{"deleted first entry", func(log *auditLog) { log.entries = log.entries[1:] }, 2, 0},
+		{"reordered entries", func(log *auditLog) {
+			first, second := log.entries[40], log.entries[41]
+			first.ID, second.ID = second.ID, first.ID
+			log.entries[40], log.entries[41] = second, first
+		}, 41, 40},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			log := newAuditLog(entries)
+			test.tamper(log)
+
+			result, err := services.NewAuditService(log).VerifyAuditChain(context.Background())
+			if err != nil {
+				t.Fatalf("VerifyAuditChain: %v", err)
+			}
+			if test.brokenAt == 0 {
+				if !result.Intact || result.Checked != test.checked || result.BrokenAt != nil || result.LastHash != log.entries[entries-1].Hash {
+					t.Errorf("VerifyAuditChain = %+v, want all %d entries intact", result, entries)
+				}
+				return
+			}
+			if result.Intact || result.BrokenAt == nil || *result.BrokenAt != test.brokenAt || result.Checked != test.checked {
+				t.Errorf("VerifyAuditChain = %+v, want it broken at entry %d", result, test.brokenAt)
+			}
+		})
+	}
+}
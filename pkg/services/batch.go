@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"song-library/configs"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+)
+
+// BatchOutcome is the result of one operation of a song batch: the song it worked on, and
+// the error it failed with, if any.
+type BatchOutcome struct {
+	SongID uint
+	Err    error
+}
+
+// ApplySongBatch runs the operations of the batch in order and returns the outcome of
+// each. Operations that do not fit their op are reported together as a
+// *utils.ValidationError before any runs. An atomic batch runs in one transaction: if an
+// operation fails, nothing is kept and every other operation reports ErrBatchRolledBack.
+// Otherwise each operation is kept or undone on its own.
+//
+// request describes the batch request for the audit log. Every operation adds a copy of it
+// that names the song, as it was before and after, in the same unit of work as the change,
+// so the entry is kept exactly when the change is.
+func (s *SongService) ApplySongBatch(ctx context.Context, batch models.SongBatchRequest, request models.AuditEntry) ([]BatchOutcome, error) {
+	if maxOperations := configs.AppSettings.BatchParams.MaxOperations; maxOperations > 0 && len(batch.Operations) > maxOperations {
+		logger.Error.Printf("[services.ApplySongBatch]: %d operations, at most %d allowed", len(batch.Operations), maxOperations)
+		return nil, utils.ErrBatchTooLarge
+	}
+	links, err := checkSongBatch(batch.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]BatchOutcome, len(batch.Operations))
+	if !batch.Atomic {
+		for i, op := range batch.Operations {
+			err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+				var err error
+				outcomes[i].SongID, err = applyAuditedBatchOperation(ctx, repos, op, links[i], request)
+				return err
+			})
+			if err != nil {
+				outcomes[i] = BatchOutcome{SongID: op.ID, Err: err}
+			}
+		}
+		return outcomes, nil
+	}
+
+	failed := -1
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		for i, op := range batch.Operations {
+			songID, err := applyAuditedBatchOperation(ctx, repos, op, links[i], request)
+			if err != nil {
+				failed = i
+				outcomes[i] = BatchOutcome{SongID: op.ID, Err: err}
+				return err
+			}
+			outcomes[i].SongID = songID
+		}
+		return nil
+	})
+	if err != nil && failed < 0 {
+		return nil, err
+	}
+	if failed >= 0 {
+		logger.Info.Printf("[services.ApplySongBatch]: Rolled back the batch, operation %d failed: %v", failed, outcomes[failed].Err)
+		for i, op := range batch.Operations {
+			if i != failed {
+				outcomes[i] = BatchOutcome{SongID: op.ID, Err: utils.ErrBatchRolledBack}
+			}
+		}
+	}
+	return outcomes, nil
+}
+
+// checkSongBatch makes sure every operation names a song unless it creates one, and
+// carries the fields its op needs and no others. It returns the normalised link of each
+// operation that sets one.
+func checkSongBatch(operations []models.SongBatchOperation) ([]models.SongLink, error) {
+	var invalid *utils.ValidationError
+	reject := func(i int, field string, err error) {
+		if invalid == nil {
+			invalid = &utils.ValidationError{Err: err}
+		}
+		invalid.Fields = append(invalid.Fields, utils.FieldError{
+			Pointer: fmt.Sprintf("/operations/%d%s", i, field),
+			Code:    err.Error(),
+		})
+	}
+
+	links := make([]models.SongLink, len(operations))
+	for i, op := range operations {
+		hasFields := op.Group != nil || op.Song != nil || op.ReleaseDate != nil || op.Text != nil || op.Link != nil || op.Language != nil
+		switch {
+		case op.Op == models.BatchCreate && op.ID != 0:
+			reject(i, "/id", utils.ErrInvalidRequestBody)
+		case op.Op != models.BatchCreate && op.ID == 0:
+			reject(i, "/id", utils.ErrMissingRequiredField)
+		}
+		switch op.Op {
+		case models.BatchCreate, models.BatchUpdate:
+			if op.Group == nil {
+				reject(i, "/group", utils.ErrInvalidGroup)
+			}
+			if op.Song == nil {
+				reject(i, "/song", utils.ErrInvalidSongTitle)
+			}
+		case models.BatchPatch:
+			if !hasFields {
+				reject(i, "", utils.ErrMissingRequiredField)
+			}
+		case models.BatchDelete, models.BatchRestore:
+			if hasFields {
+				reject(i, "", utils.ErrInvalidRequestBody)
+			}
+		}
+
+		if op.Link != nil && *op.Link != "" {
+			link, err := NormalizeLink(*op.Link)
+			if err != nil {
+				reject(i, "/link", err)
+				continue
+			}
+			links[i] = link
+		}
+	}
+	if invalid != nil {
+		return nil, invalid
+	}
+	return links, nil
+}
+
+// applyAuditedBatchOperation runs one operation on repos like applySongBatchOperation and
+// adds it to the audit log as a copy of entry.
+func applyAuditedBatchOperation(ctx context.Context, repos repository.Repositories, op models.SongBatchOperation, link models.SongLink, entry models.AuditEntry) (uint, error) {
+	var before *models.Song
+	if op.ID != 0 {
+		song, err := repos.Songs.GetSongByID(ctx, op.ID, true)
+		if err != nil {
+			return op.ID, err
+		}
+		before = song
+	}
+
+	songID, err := applySongBatchOperation(ctx, repos, op, link)
+	if err != nil {
+		return songID, err
+	}
+	after, err := repos.Songs.GetSongByID(ctx, songID, true)
+	if err != nil {
+		return songID, err
+	}
+
+	entry.SongID = &songID
+	entry.Before, entry.After = before, after
+	entry.Status = http.StatusOK
+	if op.Op == models.BatchCreate {
+		entry.Status = http.StatusCreated
+	}
+	return songID, repos.Audit.AddAuditEntry(ctx, &entry)
+}
+
+// applySongBatchOperation runs one operation on repos and returns the song it worked on.
+// link is the normalised link the operation sets, if any.
+func applySongBatchOperation(ctx context.Context, repos repository.Repositories, op models.SongBatchOperation, link models.SongLink) (uint, error) {
+	switch op.Op {
+	case models.BatchCreate:
+		return addBatchSong(ctx, repos, op, link)
+	case models.BatchUpdate:
+		return op.ID, changeSong(ctx, repos, op.ID, link, func(song *models.Song) {
+			song.Group = *op.Group
+			song.Song = *op.Song
+			song.ReleaseDate = stringValue(op.ReleaseDate)
+			song.Text = stringValue(op.Text)
+			song.Link = link.URL
+			song.Language = stringValue(op.Language)
+		})
+	case models.BatchPatch:
+		return op.ID, changeSong(ctx, repos, op.ID, link, func(song *models.Song) {
+			if op.Group != nil {
+				song.Group = *op.Group
+			}
+			if op.Song != nil {
+				song.Song = *op.Song
+			}
+			if op.ReleaseDate != nil {
+				song.ReleaseDate = *op.ReleaseDate
+			}
+			if op.Text != nil {
+				song.Text = *op.Text
+			}
+			if op.Link != nil {
+				song.Link = link.URL
+			}
+			if op.Language != nil {
+				song.Language = *op.Language
+			}
+		})
+	case models.BatchDelete:
+		return op.ID, softDeleteSong(ctx, repos, op.ID)
+	case models.BatchRestore:
+		return op.ID, repos.Songs.RestoreSong(ctx, op.ID, models.ActorFromContext(ctx))
+	default:
+		return op.ID, utils.ErrInvalidRequestBody
+	}
+}
+
+// addBatchSong adds the song an operation creates as it is, without asking the metadata
+// provider; its enriched fields are recorded as imported.
+func addBatchSong(ctx context.Context, repos repository.Repositories, op models.SongBatchOperation, link models.SongLink) (uint, error) {
+	song := &models.Song{
+		Group:       *op.Group,
+		Song:        *op.Song,
+		ReleaseDate: stringValue(op.ReleaseDate),
+		Text:        stringValue(op.Text),
+		Link:        link.URL,
+		Language:    stringValue(op.Language),
+		UpdatedBy:   models.ActorFromContext(ctx),
+	}
+
+	exists, err := repos.Songs.SongExists(ctx, song.Group, song.Song)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, utils.ErrSongAlreadyExists
+	}
+	if err := repos.Songs.AddSong(ctx, song); err != nil {
+		return 0, err
+	}
+	if link.URL != "" {
+		if err := attachLink(ctx, repos.Links, song.ID, link); err != nil {
+			return 0, err
+		}
+	}
+	return song.ID, recordProvenance(ctx, repos.Provenance, song.ID, models.SourceImport, "", filledFields(song)...)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"song-library/configs"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sync"
+	"time"
+)
+
+// idempotencySweepInterval is how often expired idempotency records are deleted. Until
+// then they are merely ignored.
+const idempotencySweepInterval = 10 * time.Minute
+
+// IdempotencyService keeps the first response to each Idempotency-Key of a client for the
+// window of idempotency_params, so retried requests are answered without running again.
+type IdempotencyService struct {
+	records repository.IdempotencyRepository
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewIdempotencyService(records repository.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{records: records}
+}
+
+// Begin reserves the key of client for the request with the given fingerprint. It returns
+// nil if the request is to be handled, and the stored response if it was handled before.
+// A key in use by a request still being handled is answered with ErrIdempotencyKeyInUse,
+// and one used for a different request with ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, client, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	now := time.Now().UTC()
+	s.sweep(ctx, now)
+
+	window := time.Duration(configs.AppSettings.IdempotencyParams.WindowHours) * time.Hour
+	existing, err := s.records.ReserveIdempotencyKey(ctx, &models.IdempotencyRecord{
+		Client:      client,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(window),
+	})
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if existing.Fingerprint != fingerprint {
+		logger.Info.Printf("[services.Begin]: %s reused idempotency key %q for another request", client, key)
+		return nil, utils.ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return nil, utils.ErrIdempotencyKeyInUse
+	}
+	return existing, nil
+}
+
+// Complete stores the response to the request that reserved the key.
+func (s *IdempotencyService) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.records.CompleteIdempotencyKey(ctx, record)
+}
+
+// Release gives up the key of a request that could not be answered, so a retry runs it
+// again.
+func (s *IdempotencyService) Release(ctx context.Context, client, key string) error {
+	return s.records.ReleaseIdempotencyKey(ctx, client, key)
+}
+
+// sweep deletes the expired records, at most once per idempotencySweepInterval.
+func (s *IdempotencyService) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < idempotencySweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if deleted, err := s.records.DeleteExpiredIdempotencyRecords(ctx, now); err != nil {
+		logger.Error.Printf("[services.sweep]: Error deleting expired idempotency records: %v", err)
+	} else if deleted > 0 {
+		logger.Info.Printf("[services.sweep]: Deleted %d expired idempotency records", deleted)
+	}
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/memory"
+	services "song-library/pkg/services"
+	"song-library/utils"
+	"testing"
+	"time"
+)
+
+// sweepCounter counts the sweeps of expired idempotency records.
+type sweepCounter struct {
+	repository.IdempotencyRepository
+	sweeps  int
+	deleted int64
+}
+
+func (r *sweepCounter) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := r.IdempotencyRepository.DeleteExpiredIdempotencyRecords(ctx, now)
+	r.sweeps++
+	r.deleted += deleted
+	return deleted, err
+}
+
+func newIdempotencyService(t *testing.T) (*services.IdempotencyService, *sweepCounter) {
+	t.Helper()
+	discardLogs()
+	configs.AppSettings.IdempotencyParams = models.IdempotencyParams{WindowHours: 24}
+	records := &sweepCounter{IdempotencyRepository: memory.NewRepositories(memory.NewStore()).Idempotency}
+	return services.NewIdempotencyService(records), records
+}
+
+func TestIdempotencyReplaysCompletedRequest(t *testing.T) {
+	idempotency, _ := newIdempotencyService(t)
+	ctx := context.Background()
+
+	if stored, err := idempotency.Begin(ctx, "key:1", "retry-1", "fingerprint"); stored != nil || err != nil {
+		t.Fatalf("Begin of a new key = %+v, %v, want nil", stored, err)
+	}
+	// Until the first request is answered, a retry must wait for it.
+	if _, err := idempotency.Begin(ctx, "key:1", "retry-1", "fingerprint"); !errors.Is(err, utils.ErrIdempotencyKeyInUse) {
+		t.Errorf("Begin while in flight = %v, want ErrIdempotencyKeyInUse", err)
+	}
+
+	response := &models.IdempotencyRecord{Client: "key:1", Key: "retry-1", Status: http.StatusCreated, Headers: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{"id":1}`)}
+	if err := idempotency.Complete(ctx, response); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	stored, err := idempotency.Begin(ctx, "key:1", "retry-1", "fingerprint")
+	if err != nil || stored == nil || stored.Status != response.Status || string(stored.Body) != string(response.Body) || !reflect.DeepEqual(stored.Headers, response.Headers) {
+		t.Fatalf("Begin after Complete = %+v, %v, want the stored response", stored, err)
+	}
+
+	if _, err := idempotency.Begin(ctx, "key:1", "retry-1", "another fingerprint"); !errors.Is(err, utils.ErrIdempotencyKeyReused) {
+		t.Errorf("Begin for another request = %v, want ErrIdempotencyKeyReused", err)
+	}
+	// Keys belong to a client.
+	if stored, err := idempotency.Begin(ctx, "key:2", "retry-1", "another fingerprint"); stored != nil || err != nil {
+		t.Errorf("Begin of another client = %+v, %v, want nil", stored, err)
+	}
+}
+
+func TestIdempotencyReleaseLetsRetryRun(t *testing.T) {
+	idempotency, _ := newIdempotencyService(t)
+	ctx := context.Background()
+
+	if _, err := idempotency.Begin(ctx, "key:1", "retry-1", "fingerprint"); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := idempotency.Release(ctx, "key:1", "retry-1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if stored, err := idempotency.Begin(ctx, "key:1", "retry-1", "fingerprint"); stored != nil || err != nil {
+		t.Errorf("Begin after Release = %+v, %v, want nil so the request runs again", stored, err)
+	}
+}
+
+func TestIdempotencySweepsExpiredRecords(t *testing.T) {
+	idempotency, records := newIdempotencyService(t)
+	ctx := context.Background()
+	past := time.Now().UTC().Add(-48 * time.Hour)
+	if _, err := records.ReserveIdempotencyKey(ctx, &models.IdempotencyRecord{Client: "key:1", Key: "old", Fingerprint: "fingerprint", Status: http.StatusOK, CreatedAt: past, ExpiresAt: past.Add(24 * time.Hour)}); err != nil {
+		t.Fatalf("ReserveIdempotencyKey: %v", err)
+	}
+
+	// An expired record is not replayed, and the first Begin deletes it.
+	if stored, err := idempotency.Begin(ctx, "key:1", "old", "fingerprint"); stored != nil || err != nil {
+		t.Errorf("Begin of an expired key = %+v, %v, want nil", stored, err)
+	}
+	if records.sweeps != 1 || records.deleted != 1 {
+		t.Errorf("sweeps = %d deleting %d records, want 1 deleting the expired one", records.sweeps, records.deleted)
+	}
+
+	// The next sweep waits for the interval.
+	if _, err := idempotency.Begin(ctx, "key:1", "new", "fingerprint"); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if records.sweeps != 1 {
+		t.Errorf("sweeps = %d, want 1 within the interval", records.sweeps)
+	}
+}
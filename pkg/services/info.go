@@ -1,14 +1,26 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"song-library/logger"
 	"song-library/models"
 	"song-library/pkg/repository"
 	"song-library/utils"
+	"strings"
+	"time"
 )
 
-func GetSongDetail(group, song string) (models.SongDetail, error) {
-	songDetails, err := repository.GetInfoByGroup(group)
+type SongDetailService struct {
+	details repository.SongDetailRepository
+}
+
+func NewSongDetailService(details repository.SongDetailRepository) *SongDetailService {
+	return &SongDetailService{details: details}
+}
+
+func (s *SongDetailService) GetSongDetail(ctx context.Context, group, song string) (models.SongDetail, error) {
+	songDetails, err := s.details.GetInfoByGroup(ctx, group)
 	if err != nil {
 		logger.Error.Printf("[services.GetSongDetail]: Error checking song exists: %s", err.Error())
 		return models.SongDetail{}, err
@@ -18,7 +30,7 @@ func GetSongDetail(group, song string) (models.SongDetail, error) {
 		return models.SongDetail{}, utils.ErrGroupNotFound
 	}
 
-	songExists, err := repository.GetInfoBySong(song)
+	songExists, err := s.details.GetInfoBySong(ctx, song)
 	if err != nil {
 		logger.Error.Printf("[services.GetSongDetail]: Error checking song: %s", err.Error())
 		return models.SongDetail{}, err
@@ -28,7 +40,7 @@ func GetSongDetail(group, song string) (models.SongDetail, error) {
 		return models.SongDetail{}, utils.ErrSongNotFound
 	}
 
-	songDetail, err := repository.GetSongDetail(group, song)
+	songDetail, err := s.details.GetSongDetail(ctx, group, song)
 	if err != nil {
 		logger.Error.Printf("[services.GetSongDetail]: Error getting song detail: %s", err.Error())
 		return models.SongDetail{}, err
@@ -36,3 +48,103 @@ func GetSongDetail(group, song string) (models.SongDetail, error) {
 
 	return songDetail, nil
 }
+
+func (s *SongDetailService) AddSongDetail(ctx context.Context, songDetail *models.SongDetail) error {
+	if err := validateSongDetail(songDetail); err != nil {
+		return err
+	}
+
+	exists, err := s.details.SongDetailExists(ctx, songDetail.Group, songDetail.Song)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.ErrSongAlreadyExists
+	}
+
+	return s.details.AddSongDetail(ctx, songDetail)
+}
+
+func (s *SongDetailService) UpdateSongDetail(ctx context.Context, group, song string, songDetail *models.SongDetail) error {
+	if err := validateSongDetail(songDetail); err != nil {
+		return err
+	}
+
+	if songDetail.Group != group || songDetail.Song != song {
+		exists, err := s.details.SongDetailExists(ctx, songDetail.Group, songDetail.Song)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return utils.ErrSongAlreadyExists
+		}
+	}
+
+	return s.details.UpdateSongDetail(ctx, group, song, songDetail)
+}
+
+func (s *SongDetailService) DeleteSongDetail(ctx context.Context, group, song string) error {
+	return s.details.DeleteSongDetail(ctx, group, song)
+}
+
+func (s *SongDetailService) BulkUpsertSongDetails(ctx context.Context, songDetails []models.SongDetail) (created, updated int, err error) {
+	if len(songDetails) == 0 {
+		return 0, 0, utils.ErrInvalidRequestBody
+	}
+
+	// Every invalid song detail is reported, not just the first one.
+	var invalid *utils.ValidationError
+	reject := func(i int, err error) {
+		if invalid == nil {
+			invalid = &utils.ValidationError{Err: err}
+		}
+		invalid.Fields = append(invalid.Fields, utils.FieldError{
+			Pointer: fmt.Sprintf("/%d", i),
+			Code:    err.Error(),
+		})
+	}
+	seen := make(map[[2]string]bool, len(songDetails))
+	for i := range songDetails {
+		if err := validateSongDetail(&songDetails[i]); err != nil {
+			reject(i, err)
+			continue
+		}
+		key := [2]string{songDetails[i].Group, songDetails[i].Song}
+		if seen[key] {
+			reject(i, utils.ErrSongAlreadyExists)
+		}
+		seen[key] = true
+	}
+	if invalid != nil {
+		return 0, 0, invalid
+	}
+
+	return s.details.UpsertSongDetails(ctx, songDetails)
+}
+
+// validateSongDetail trims the song detail in place and checks it the same way the
+// provider response is consumed by AddSong.
+func validateSongDetail(songDetail *models.SongDetail) error {
+	songDetail.Group = strings.TrimSpace(songDetail.Group)
+	songDetail.Song = strings.TrimSpace(songDetail.Song)
+	songDetail.ReleaseDate = strings.TrimSpace(songDetail.ReleaseDate)
+	songDetail.Link = strings.TrimSpace(songDetail.Link)
+
+	if songDetail.Group == "" {
+		return utils.ErrInvalidGroup
+	}
+	if songDetail.Song == "" {
+		return utils.ErrInvalidSongTitle
+	}
+	if songDetail.ReleaseDate != "" {
+		if _, err := time.Parse(models.ReleaseDateLayout, songDetail.ReleaseDate); err != nil {
+			return utils.ErrInvalidReleaseDate
+		}
+	}
+	if songDetail.Link != "" {
+		if _, err := NormalizeLink(songDetail.Link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
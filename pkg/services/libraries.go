@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+)
+
+// librarySlugPattern is what a library slug, as sent in the X-Library header, may look like.
+var librarySlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type LibraryService struct {
+	libraries repository.LibraryRepository
+}
+
+func NewLibraryService(libraries repository.LibraryRepository) *LibraryService {
+	return &LibraryService{libraries: libraries}
+}
+
+func (s *LibraryService) GetLibraries(ctx context.Context) ([]models.Library, error) {
+	return s.libraries.GetLibraries(ctx)
+}
+
+func (s *LibraryService) CreateLibrary(ctx context.Context, request models.NewLibraryRequest) (*models.Library, error) {
+	slug := strings.TrimSpace(request.Slug)
+	name := strings.TrimSpace(request.Name)
+	if !librarySlugPattern.MatchString(slug) || name == "" {
+		return nil, utils.ErrInvalidLibraryRequest
+	}
+
+	library := &models.Library{Slug: slug, Name: name}
+	if err := s.libraries.AddLibrary(ctx, library); err != nil {
+		return nil, err
+	}
+	return library, nil
+}
+
+// GetLibraryBySlug returns the library with the given slug, or ErrLibraryNotFound.
+func (s *LibraryService) GetLibraryBySlug(ctx context.Context, slug string) (*models.Library, error) {
+	library, err := s.libraries.GetLibraryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if library == nil {
+		return nil, utils.ErrLibraryNotFound
+	}
+	return library, nil
+}
+
+// validateLibraryBinding checks that a key or user limited to libraryID may have the given
+// scopes and returns invalid if not: the library must exist, and the admin scope reaches
+// every library, so it cannot be limited to one.
+func validateLibraryBinding(ctx context.Context, libraries repository.LibraryRepository, libraryID *uint, scopes []string, invalid error) error {
+	if libraryID == nil {
+		return nil
+	}
+	for _, scope := range scopes {
+		if scope == models.ScopeAdmin {
+			return invalid
+		}
+	}
+	library, err := libraries.GetLibraryByID(ctx, *libraryID)
+	if err != nil {
+		return err
+	}
+	if library == nil {
+		return invalid
+	}
+	return nil
+}
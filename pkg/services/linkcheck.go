@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"song-library/configs"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	linkCheckBatchSize    = 200
+	linkCheckMaxRedirects = 10
+)
+
+var (
+	// errAddressRefused is returned for hosts that resolve to an address the checker must not
+	// reach, so stored links cannot be used to probe the server's own network.
+	errAddressRefused   = errors.New("address refused")
+	errRedirectRefused  = errors.New("redirect refused")
+	errTooManyRedirects = errors.New("too many redirects")
+
+	// nonPublicPrefixes are the ranges netip.Addr has no predicate for: shared address space
+	// (carrier-grade NAT), IETF protocol assignments, benchmarking, reserved, and the NAT64
+	// and 6to4 prefixes that embed IPv4 addresses.
+	nonPublicPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("192.0.0.0/24"),
+		netip.MustParsePrefix("198.18.0.0/15"),
+		netip.MustParsePrefix("240.0.0.0/4"),
+		netip.MustParsePrefix("64:ff9b::/96"),
+		netip.MustParsePrefix("64:ff9b:1::/48"),
+		netip.MustParsePrefix("2002::/16"),
+	}
+)
+
+// LinkChecker periodically probes stored song links and keeps track of their health.
+type LinkChecker struct {
+	links repository.LinkRepository
+	songs *SongService
+}
+
+func NewLinkChecker(links repository.LinkRepository, songs *SongService) *LinkChecker {
+	return &LinkChecker{
+		links: links,
+		songs: songs,
+	}
+}
+
+// Start probes all stored song links right away and then once per configured interval
+// until the context is cancelled.
+func (lc *LinkChecker) Start(ctx context.Context) {
+	params := configs.AppSettings.LinkCheckParams
+	interval := time.Duration(params.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	logger.Info.Printf("[services.LinkChecker.Start]: Checking links every %s", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := lc.CheckAll(ctx); err != nil {
+			logger.Error.Printf("[services.LinkChecker.Start]: Error checking links: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes every link of songs that are not deleted, with at most the configured
+// number of probes in flight and a minimum pause between requests to the same host.
+func (lc *LinkChecker) CheckAll(ctx context.Context) error {
+	// Songs it re-enriches are recorded as changed by the checker.
+	ctx = models.ContextWithPrincipal(ctx, &models.Principal{Name: "link-checker"})
+	params := configs.AppSettings.LinkCheckParams
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	timeout := time.Duration(params.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	run := &linkCheckRun{
+		checker: lc,
+		client:  newLinkCheckClient(timeout),
+		limiter: newHostLimiter(time.Duration(params.PerHostIntervalMs) * time.Millisecond),
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	checked := 0
+
+	var afterID uint
+	for ctx.Err() == nil {
+		links, err := lc.links.GetLinksAfterID(ctx, afterID, linkCheckBatchSize)
+		if err != nil {
+			wg.Wait()
+			return err
+		}
+		if len(links) == 0 {
+			break
+		}
+
+		for _, link := range links {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(link models.SongLink) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				run.check(ctx, link)
+			}(link)
+			checked++
+		}
+		afterID = links[len(links)-1].ID
+	}
+
+	wg.Wait()
+	logger.Info.Printf("[services.LinkChecker.CheckAll]: Checked %d links", checked)
+	return ctx.Err()
+}
+
+func (s *SongService) GetBrokenLinks(ctx context.Context, page, limit int) ([]models.BrokenLink, error) {
+	if page <= 0 || limit <= 0 || limit > 100 {
+		logger.Error.Printf("services.GetBrokenLinks: page %d or limit %d", page, limit)
+		return nil, utils.ErrInvalidPaginationParams
+	}
+	return s.links.GetBrokenLinks(ctx, brokenAfterFailures(), page, limit)
+}
+
+func brokenAfterFailures() int {
+	if failures := configs.AppSettings.LinkCheckParams.BrokenAfterFailures; failures > 0 {
+		return failures
+	}
+	return 1
+}
+
+// linkCheckRun holds the state shared by the probes of one CheckAll call.
+type linkCheckRun struct {
+	checker *LinkChecker
+	client  *http.Client
+	limiter *hostLimiter
+}
+
+func (lc *linkCheckRun) check(ctx context.Context, link models.SongLink) {
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return
+	}
+	if err := lc.limiter.Wait(ctx, u.Host); err != nil {
+		return
+	}
+
+	statusCode, err := lc.probe(ctx, link.URL)
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	link.StatusCode = statusCode
+	link.LastCheckedAt = &now
+	switch {
+	case err != nil:
+		logger.Debug.Printf("[services.LinkChecker]: Probing link %d failed: %v", link.ID, err)
+		link.LastError = probeError(err)
+		link.FailureStreak++
+	case statusCode >= http.StatusBadRequest:
+		link.LastError = http.StatusText(statusCode)
+		link.FailureStreak++
+	default:
+		link.LastError = ""
+		link.FailureStreak = 0
+	}
+
+	if err := lc.checker.links.UpdateLinkHealth(ctx, &link); err != nil {
+		return
+	}
+
+	if link.FailureStreak == brokenAfterFailures() {
+		logger.Warning.Printf("[services.LinkChecker]: Link %d of song %d is broken: %s", link.ID, link.SongID, link.LastError)
+		if configs.AppSettings.LinkCheckParams.ReenrichBrokenSongs {
+			if err := lc.checker.songs.ReenrichSong(ctx, link.SongID); err != nil {
+				logger.Error.Printf("[services.LinkChecker]: Error re-enriching song %d: %v", link.SongID, err)
+			}
+		}
+	}
+}
+
+// probe requests the link with HEAD and falls back to GET for servers that do not support it.
+func (lc *linkCheckRun) probe(ctx context.Context, link string) (int, error) {
+	statusCode, err := lc.do(ctx, http.MethodHead, link)
+	if err == nil && statusCode != http.StatusMethodNotAllowed && statusCode != http.StatusNotImplemented {
+		return statusCode, nil
+	}
+	return lc.do(ctx, http.MethodGet, link)
+}
+
+func (lc *linkCheckRun) do(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", configs.AppSettings.AppParams.ServerName+" link checker")
+
+	resp, err := lc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	if err := resp.Body.Close(); err != nil {
+		logger.Error.Printf("[services.LinkChecker] Failed to close response body: %v", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// newLinkCheckClient returns a client that only connects to public addresses and follows
+// redirects only to links NormalizeLink would accept. Proxies from the environment are
+// ignored, since they would connect on the checker's behalf without these checks.
+func newLinkCheckClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialPublicAddress(dialer),
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: checkLinkRedirect,
+	}
+}
+
+// dialPublicAddress resolves the host itself and connects to the addresses it got, so the
+// address checked is the one connected to. Hosts with any non-public address are refused.
+func dialPublicAddress(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if !isPublicAddress(addr) {
+				return nil, fmt.Errorf("%s resolves to %s: %w", host, addr, errAddressRefused)
+			}
+		}
+
+		for _, addr := range addrs {
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// isPublicAddress reports whether the address belongs to the public internet rather than to
+// the machine, a private or link-local network (which holds cloud metadata services at
+// 169.254.169.254) or a reserved range.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkLinkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= linkCheckMaxRedirects {
+		return errTooManyRedirects
+	}
+	u := req.URL
+	if (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || !hasDefaultPort(u) || !isPublicHostname(strings.ToLower(u.Hostname())) {
+		return fmt.Errorf("redirect to %s: %w", u.Redacted(), errRedirectRefused)
+	}
+	return nil
+}
+
+// probeError describes why a probe failed without the text of the error itself, which can
+// hold addresses and responses of the hosts probed and is served by GET /songs/broken-links.
+func probeError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errAddressRefused):
+		return "address not allowed"
+	case errors.Is(err, errRedirectRefused):
+		return "redirect not allowed"
+	case errors.Is(err, errTooManyRedirects):
+		return "too many redirects"
+	case errors.As(err, &dnsErr):
+		return "host not found"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// hostLimiter hands out request slots per host that are at least interval apart.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+func (hl *hostLimiter) Wait(ctx context.Context, host string) error {
+	if hl.interval <= 0 {
+		return nil
+	}
+
+	hl.mu.Lock()
+	now := time.Now()
+	slot := hl.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	hl.next[host] = slot.Add(hl.interval)
+	hl.mu.Unlock()
+
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReenrichSong fetches the song details from the metadata provider again and overwrites
+// the enriched fields the provider has values for.
+func (s *SongService) ReenrichSong(ctx context.Context, id uint) error {
+	song, err := s.GetSongByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	songDetail, hash, err := fetchSongDetail(ctx, song.Group, song.Song)
+	if err != nil {
+		return err
+	}
+
+	var link models.SongLink
+	if songDetail.Link != "" {
+		link, err = NormalizeLink(songDetail.Link)
+		if err != nil {
+			logger.Error.Printf("[services.ReenrichSong] Provider returned invalid link %q: %v", songDetail.Link, err)
+		}
+	}
+
+	// The song is read again inside the transaction, it may have changed during the request.
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		song, err := findSong(ctx, repos.Songs, id)
+		if err != nil {
+			return err
+		}
+
+		before := *song
+		if songDetail.ReleaseDate != "" {
+			song.ReleaseDate = songDetail.ReleaseDate
+		}
+		if songDetail.Text != "" {
+			song.Text = songDetail.Text
+		}
+		if link.URL != "" {
+			song.Link = link.URL
+		}
+
+		fields := changedFields(&before, song)
+		if len(fields) == 0 {
+			logger.Info.Printf("[services.ReenrichSong]: Provider has no new data for song %d", id)
+			return nil
+		}
+		song.UpdatedAt = time.Now()
+		song.UpdatedBy = models.ActorFromContext(ctx)
+
+		if err := repos.Songs.UpdateSong(ctx, song); err != nil {
+			return fmt.Errorf("updating song %d: %w", id, err)
+		}
+		if link.URL != "" {
+			if err := attachLink(ctx, repos.Links, id, link); err != nil {
+				return err
+			}
+		}
+		return recordProvenance(ctx, repos.Provenance, id, models.SourceProvider, hash, fields...)
+	})
+}
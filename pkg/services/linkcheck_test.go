@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"song-library/configs"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository/memory"
+	services "song-library/pkg/services"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLinkCheckerRefusesNonPublicAddresses(t *testing.T) {
+	discardLogs()
+	configs.AppSettings.LinkCheckParams = models.LinkCheckParams{TimeoutSeconds: 2}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	ctx := context.Background()
+	song := &models.Song{Group: "Muse", Song: "Uprising"}
+	if err := repos.Songs.AddSong(ctx, song); err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+	// Links stored before NormalizeLink refused such hosts are still checked.
+	targets := []string{server.URL, "http://localhost:1/", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.1/", "http://[::1]:1/"}
+	for _, target := range targets {
+		if err := repos.Links.AddLink(ctx, &models.SongLink{SongID: song.ID, Platform: models.PlatformGeneric, URL: target}); err != nil {
+			t.Fatalf("AddLink(%q): %v", target, err)
+		}
+	}
+
+	checker := services.NewLinkChecker(repos.Links, services.NewSongService(repos, memory.NewUnitOfWork(store)))
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll: %v", err)
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("the server on the loopback address got %d requests, want none", n)
+	}
+	links, err := repos.Links.GetLinksBySongID(ctx, song.ID)
+	if err != nil {
+		t.Fatalf("GetLinksBySongID: %v", err)
+	}
+	for _, link := range links {
+		if link.LastError != "address not allowed" || link.FailureStreak != 1 || link.StatusCode != 0 {
+			t.Errorf("link %s has last error %q, failure streak %d and status %d, want %q, 1 and 0", link.URL, link.LastError, link.FailureStreak, link.StatusCode, "address not allowed")
+		}
+	}
+}
+
+// discardLogs gives the package loggers somewhere to write, as logger.Init does for the
+// server.
+func discardLogs() {
+	for _, l := range []**log.Logger{&logger.Info, &logger.Error, &logger.Warning, &logger.Debug} {
+		*l = log.New(io.Discard, "", 0)
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"regexp"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+)
+
+var (
+	youTubeIDPattern       = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	spotifyIDPattern       = regexp.MustCompile(`^[A-Za-z0-9]{22}$`)
+	appleMusicIDPattern    = regexp.MustCompile(`^[0-9]+$`)
+	soundCloudTokenPattern = regexp.MustCompile(`^s-[A-Za-z0-9]+$`)
+	spotifyTypes           = map[string]bool{"track": true, "album": true, "artist": true, "playlist": true, "episode": true, "show": true}
+	topLevelDomainPattern  = regexp.MustCompile(`^([a-z]{2,63}|xn--[a-z0-9-]{1,59})$`)
+	// reservedDomains never name public sites: special-use names (RFC 6761, RFC 8375), mDNS
+	// (.local), names private networks commonly use and .internal, under which cloud
+	// providers serve instance metadata.
+	reservedDomains = []string{"localhost", "local", "localdomain", "internal", "intranet", "lan", "home", "corp", "home.arpa", "arpa", "test", "invalid", "example"}
+)
+
+// NormalizeLink validates a link, detects its platform and rewrites it to the canonical
+// form of that platform, so the same video or track is always stored the same way.
+func NormalizeLink(raw string) (models.SongLink, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	if strings.HasPrefix(raw, "spotify:") {
+		return normalizeSpotifyURI(raw)
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !isPublicHostname(host) {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	segments := pathSegments(u.Path)
+
+	switch bare := strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m."); {
+	case bare == "youtu.be" || bare == "youtube.com" || bare == "music.youtube.com":
+		return normalizeYouTube(bare, segments, u.Query())
+	case bare == "open.spotify.com":
+		return normalizeSpotify(segments)
+	case bare == "music.apple.com":
+		return normalizeAppleMusic(segments, u.Query())
+	case strings.HasSuffix(bare, ".bandcamp.com"):
+		return normalizeBandcamp(bare, segments)
+	case bare == "soundcloud.com":
+		return normalizeSoundCloud(segments)
+	}
+
+	return normalizeGeneric(u, host)
+}
+
+// isPublicHostname reports whether the host is a domain name that can only belong to a
+// public site. IP addresses, including shorthands such as 127.1 or 0x7f000001, are refused
+// along with names under reserved domains, so stored links cannot point the link checker
+// at the server itself or its network. Names that resolve to private addresses are caught
+// when the link checker connects.
+func isPublicHostname(host string) bool {
+	if host == "" || !strings.Contains(host, ".") || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+		return false
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if !topLevelDomainPattern.MatchString(labels[len(labels)-1]) {
+		return false
+	}
+	for _, domain := range reservedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeYouTube(host string, segments []string, query url.Values) (models.SongLink, error) {
+	var id string
+	switch {
+	case host == "youtu.be" && len(segments) > 0:
+		id = segments[0]
+	case len(segments) > 0 && segments[0] == "watch":
+		id = query.Get("v")
+	case len(segments) > 1 && (segments[0] == "shorts" || segments[0] == "embed" || segments[0] == "v" || segments[0] == "live"):
+		id = segments[1]
+	}
+
+	if !youTubeIDPattern.MatchString(id) {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	return models.SongLink{
+		Platform:   models.PlatformYouTube,
+		URL:        "https://www.youtube.com/watch?v=" + id,
+		PlatformID: id,
+	}, nil
+}
+
+func normalizeSpotify(segments []string) (models.SongLink, error) {
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+		segments = segments[1:]
+	}
+	if len(segments) < 2 {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	return spotifyLink(segments[0], segments[1])
+}
+
+func normalizeSpotifyURI(raw string) (models.SongLink, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 3 {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	return spotifyLink(parts[1], parts[2])
+}
+
+func spotifyLink(kind, id string) (models.SongLink, error) {
+	if !spotifyTypes[kind] || !spotifyIDPattern.MatchString(id) {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	return models.SongLink{
+		Platform:   models.PlatformSpotify,
+		URL:        "https://open.spotify.com/" + kind + "/" + id,
+		PlatformID: kind + ":" + id,
+	}, nil
+}
+
+// normalizeAppleMusic handles links like music.apple.com/us/album/name/123?i=456, where the
+// optional "i" parameter selects a track of the album.
+func normalizeAppleMusic(segments []string, query url.Values) (models.SongLink, error) {
+	if len(segments) < 3 {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	id := segments[len(segments)-1]
+	if !appleMusicIDPattern.MatchString(id) {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	canonical := "https://music.apple.com/" + strings.Join(segments, "/")
+	if track := query.Get("i"); track != "" {
+		if !appleMusicIDPattern.MatchString(track) {
+			return models.SongLink{}, utils.ErrInvalidLink
+		}
+		canonical += "?i=" + track
+		id = track
+	}
+
+	return models.SongLink{
+		Platform:   models.PlatformAppleMusic,
+		URL:        canonical,
+		PlatformID: id,
+	}, nil
+}
+
+func normalizeBandcamp(host string, segments []string) (models.SongLink, error) {
+	artist := strings.TrimSuffix(host, ".bandcamp.com")
+	if artist == "" || strings.Contains(artist, ".") {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	if len(segments) < 2 || (segments[0] != "track" && segments[0] != "album") {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	return models.SongLink{
+		Platform:   models.PlatformBandcamp,
+		URL:        "https://" + host + "/" + segments[0] + "/" + segments[1],
+		PlatformID: artist + "/" + segments[0] + "/" + segments[1],
+	}, nil
+}
+
+// normalizeSoundCloud handles tracks (soundcloud.com/artist/track) and sets
+// (soundcloud.com/artist/sets/name), either of which may end with the secret token of a
+// private link, such as s-AbC12.
+func normalizeSoundCloud(segments []string) (models.SongLink, error) {
+	if len(segments) < 2 {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+
+	n := 2
+	if segments[1] == "sets" {
+		if len(segments) < 3 {
+			return models.SongLink{}, utils.ErrInvalidLink
+		}
+		n = 3
+	}
+	if len(segments) > n && soundCloudTokenPattern.MatchString(segments[n]) {
+		n++
+	}
+
+	id := strings.Join(segments[:n], "/")
+	return models.SongLink{
+		Platform:   models.PlatformSoundCloud,
+		URL:        "https://soundcloud.com/" + id,
+		PlatformID: id,
+	}, nil
+}
+
+// normalizeGeneric lower-cases the scheme and host, drops default ports, fragments and
+// utm_* tracking parameters and keeps everything else as given. Links to any other port are
+// refused, since public song pages are served on the default ones.
+func normalizeGeneric(u *url.URL, host string) (models.SongLink, error) {
+	canonical := *u
+	canonical.Scheme = strings.ToLower(u.Scheme)
+	canonical.Fragment = ""
+	canonical.RawFragment = ""
+
+	if !hasDefaultPort(&canonical) {
+		return models.SongLink{}, utils.ErrInvalidLink
+	}
+	canonical.Host = host
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	canonical.RawQuery = query.Encode()
+
+	return models.SongLink{
+		Platform: models.PlatformGeneric,
+		URL:      canonical.String(),
+	}, nil
+}
+
+// hasDefaultPort reports whether the URL names no port or the default one of its scheme.
+func hasDefaultPort(u *url.URL) bool {
+	port := u.Port()
+	return port == "" || (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443")
+}
+
+func pathSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func (s *SongService) GetSongLinks(ctx context.Context, songID uint) ([]models.SongLink, error) {
+	if _, err := s.GetSongByID(ctx, songID); err != nil {
+		return nil, err
+	}
+	return s.links.GetLinksBySongID(ctx, songID)
+}
+
+func (s *SongService) AddSongLink(ctx context.Context, songID uint, rawURL string) (*models.SongLink, error) {
+	link, err := NormalizeLink(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	link.SongID = songID
+
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if _, err := findSong(ctx, repos.Songs, songID); err != nil {
+			return err
+		}
+
+		exists, err := repos.Links.LinkExists(ctx, songID, link.URL)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return utils.ErrLinkAlreadyExists
+		}
+
+		return repos.Links.AddLink(ctx, &link)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (s *SongService) DeleteSongLink(ctx context.Context, songID, linkID uint) error {
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		song, err := findSong(ctx, repos.Songs, songID)
+		if err != nil {
+			return err
+		}
+
+		link, err := repos.Links.GetLinkByID(ctx, songID, linkID)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Links.DeleteLink(ctx, songID, linkID); err != nil {
+			return err
+		}
+
+		if song.Link != link.URL {
+			return nil
+		}
+		if err := repos.Songs.SetSongLink(ctx, songID, ""); err != nil {
+			return err
+		}
+		return recordProvenance(ctx, repos.Provenance, songID, models.SourceManual, "", models.FieldLink)
+	})
+}
+
+// attachLink adds the primary link of a song to its links collection if it is not there yet.
+func attachLink(ctx context.Context, links repository.LinkRepository, songID uint, link models.SongLink) error {
+	link.SongID = songID
+
+	exists, err := links.LinkExists(ctx, songID, link.URL)
+	if err != nil || exists {
+		return err
+	}
+	if err := links.AddLink(ctx, &link); err != nil {
+		logger.Error.Printf("[services.attachLink]: Error adding link to song %d: %v", songID, err)
+		return err
+	}
+	return nil
+}
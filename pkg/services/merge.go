@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// lyricsDuplicateThreshold is the lyrics similarity at which two songs of the same group are
+// reported as duplicates even though their titles differ.
+const lyricsDuplicateThreshold = 0.9
+
+var (
+	// titleAnnotationPattern matches bracketed parts of a title such as "(Remastered)" or "[Live]".
+	titleAnnotationPattern = regexp.MustCompile(`[(\[][^)\]]*[)\]]`)
+
+	mergeableFields = map[string]bool{
+		"group":                 true,
+		"song":                  true,
+		models.FieldReleaseDate: true,
+		models.FieldText:        true,
+		models.FieldLink:        true,
+	}
+)
+
+// GetDuplicateSongs groups active songs of the same group whose titles match once bracketed
+// annotations and " - ..." suffixes are dropped, or whose lyrics are nearly identical.
+// Clusters with a lyrics similarity below minSimilarity are left out.
+func (s *SongService) GetDuplicateSongs(ctx context.Context, minSimilarity float64, page, limit int) ([]models.DuplicateCluster, error) {
+	if page <= 0 || limit <= 0 || limit > 100 {
+		logger.Error.Printf("[services.GetDuplicateSongs]: page %d or limit %d", page, limit)
+		return nil, utils.ErrInvalidPaginationParams
+	}
+	if minSimilarity < 0 || minSimilarity > 1 {
+		return nil, utils.ErrInvalidRequestParameter
+	}
+
+	// Only groups with more than one song can hold duplicates, and songs of different
+	// libraries are never duplicates of each other, so the songs are loaded group by group.
+	groups, err := s.songs.GetSharedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := (page - 1) * limit
+	end := start + limit
+	clusters := []models.DuplicateCluster{}
+	for _, group := range groups {
+		// Clusters are ordered by their first song and groups by their first song, so once
+		// the requested page is full no later group can add a cluster to it.
+		if len(clusters) >= end && clusters[end-1].Songs[0].ID < group.FirstSongID {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		songs, err := s.songs.GetGroupSongs(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range duplicateClusters(songs) {
+			if cluster.LyricsSimilarity != nil && *cluster.LyricsSimilarity < minSimilarity {
+				continue
+			}
+			cluster.Group = group.GroupKey
+			clusters = append(clusters, cluster)
+		}
+		sort.SliceStable(clusters, func(i, j int) bool {
+			return clusters[i].Songs[0].ID < clusters[j].Songs[0].ID
+		})
+	}
+
+	if start >= len(clusters) {
+		return []models.DuplicateCluster{}, nil
+	}
+	if end > len(clusters) {
+		end = len(clusters)
+	}
+	return clusters[start:end], nil
+}
+
+// duplicateClusters clusters the songs of one group, which are ordered by ID.
+func duplicateClusters(songs []models.Song) []models.DuplicateCluster {
+	titles := make([]string, len(songs))
+	words := make([]map[string]bool, len(songs))
+	parent := make([]int, len(songs))
+	for i, song := range songs {
+		titles[i] = baseTitle(song.Song)
+		words[i] = lyricsWords(song.Text)
+		parent[i] = i
+	}
+
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range songs {
+		for j := i + 1; j < len(songs); j++ {
+			if titles[i] == titles[j] || (len(words[i]) > 0 && len(words[j]) > 0 && jaccard(words[i], words[j]) >= lyricsDuplicateThreshold) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range songs {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], i)
+	}
+
+	var clusters []models.DuplicateCluster
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+
+		cluster := models.DuplicateCluster{Title: titles[r]}
+		for _, i := range members[r] {
+			cluster.Songs = append(cluster.Songs, songs[i])
+			for _, j := range members[r] {
+				if j <= i || len(words[i]) == 0 || len(words[j]) == 0 {
+					continue
+				}
+				if similarity := jaccard(words[i], words[j]); cluster.LyricsSimilarity == nil || similarity < *cluster.LyricsSimilarity {
+					cluster.LyricsSimilarity = &similarity
+				}
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// baseTitle normalises a title and strips annotations, so "Song 2 (Remastered)" and
+// "Song 2 - 2012 Remaster" both become "song 2".
+func baseTitle(title string) string {
+	key := titleAnnotationPattern.ReplaceAllString(models.NormalizeKey(title), " ")
+	if i := strings.Index(key, " - "); i > 0 {
+		key = key[:i]
+	}
+	return strings.Join(strings.Fields(key), " ")
+}
+
+func lyricsWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(models.NormalizeKey(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// MergeSongs folds the source songs into the target. Each field listed in the request is
+// taken from the given song, the others keep the target's value. The sources' links move to
+// the target, the sources are soft deleted and their IDs redirect to the target.
+func (s *SongService) MergeSongs(ctx context.Context, request models.MergeSongsRequest) (*models.Song, error) {
+	if request.TargetID == 0 || len(request.SourceIDs) == 0 {
+		return nil, utils.ErrInvalidMergeRequest
+	}
+	for field := range request.Fields {
+		if !mergeableFields[field] {
+			return nil, utils.ErrInvalidMergeRequest
+		}
+	}
+
+	err := s.uow.Do(ctx, func(repos repository.Repositories) error {
+		target, err := findSong(ctx, repos.Songs, request.TargetID)
+		if err != nil {
+			return err
+		}
+		songs := map[uint]*models.Song{target.ID: target}
+		for _, id := range request.SourceIDs {
+			if songs[id] != nil {
+				return utils.ErrInvalidMergeRequest
+			}
+			source, err := findSong(ctx, repos.Songs, id)
+			if err != nil {
+				return err
+			}
+			songs[id] = source
+		}
+
+		merged := *target
+		for field, songID := range request.Fields {
+			from := songs[songID]
+			if from == nil {
+				return utils.ErrInvalidMergeRequest
+			}
+			switch field {
+			case "group":
+				merged.Group = from.Group
+			case "song":
+				merged.Song = from.Song
+			case models.FieldReleaseDate:
+				merged.ReleaseDate = from.ReleaseDate
+			case models.FieldText:
+				merged.Text = from.Text
+			case models.FieldLink:
+				merged.Link = from.Link
+			}
+		}
+
+		// The sources go first, so the target may take over the group and title of one of them.
+		for _, id := range request.SourceIDs {
+			if err := repos.Songs.SoftDeleteSong(ctx, id, models.ActorFromContext(ctx)); err != nil {
+				return err
+			}
+		}
+		merged.UpdatedAt = time.Now()
+		merged.UpdatedBy = models.ActorFromContext(ctx)
+		if err := repos.Songs.UpdateSong(ctx, &merged); err != nil {
+			return err
+		}
+
+		for _, id := range request.SourceIDs {
+			for _, link := range songs[id].Links {
+				link.ID = 0
+				if err := attachLink(ctx, repos.Links, target.ID, link); err != nil {
+					return err
+				}
+			}
+		}
+		if err := copyProvenance(ctx, repos.Provenance, target.ID, request.Fields); err != nil {
+			return err
+		}
+
+		if err := repos.Merges.SaveRedirects(ctx, target.ID, request.SourceIDs); err != nil {
+			return err
+		}
+		merge := &models.SongMerge{
+			TargetID:  target.ID,
+			SourceIDs: request.SourceIDs,
+			Fields:    request.Fields,
+			CreatedAt: merged.UpdatedAt,
+		}
+		if merge.Fields == nil {
+			merge.Fields = map[string]uint{}
+		}
+		return repos.Merges.AddMerge(ctx, merge)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSongByID(ctx, request.TargetID)
+}
+
+// copyProvenance gives the target the provenance of every enriched field it took from
+// another song. A field without a recorded origin counts as set by hand.
+func copyProvenance(ctx context.Context, provenance repository.ProvenanceRepository, targetID uint, fields map[string]uint) error {
+	var records []models.SongFieldProvenance
+	var manual []string
+	for field, songID := range fields {
+		if songID == targetID || field == "group" || field == "song" {
+			continue
+		}
+
+		sourceRecords, err := provenance.GetProvenanceBySongID(ctx, songID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, record := range sourceRecords {
+			if record.Field == field {
+				record.ID = 0
+				record.SongID = targetID
+				records = append(records, record)
+				found = true
+			}
+		}
+		if !found {
+			manual = append(manual, field)
+		}
+	}
+
+	if len(records) > 0 {
+		if err := provenance.SaveProvenance(ctx, records); err != nil {
+			return err
+		}
+	}
+	return recordProvenance(ctx, provenance, targetID, models.SourceManual, "", manual...)
+}
+
+// ResolveSongRedirect returns the ID of the song the given one was merged into, or 0.
+func (s *SongService) ResolveSongRedirect(ctx context.Context, id uint) (uint, error) {
+	redirect, err := s.merges.GetRedirect(ctx, id)
+	if err != nil || redirect == nil {
+		return 0, err
+	}
+	return redirect.ToID, nil
+}
+
+func (s *SongService) GetSongMerges(ctx context.Context, id uint) ([]models.SongMerge, error) {
+	if _, err := s.GetSongByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.merges.GetMergesBySongID(ctx, id)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"time"
+)
+
+func (s *SongService) GetSongProvenance(ctx context.Context, id uint) ([]models.SongFieldProvenance, error) {
+	if _, err := findSong(ctx, s.songs, id); err != nil {
+		return nil, err
+	}
+
+	records, err := s.provenance.GetProvenanceBySongID(ctx, id)
+	if err != nil {
+		logger.Error.Printf("[services.GetSongProvenance]: Error getting provenance: %v", err)
+		return nil, err
+	}
+	return records, nil
+}
+
+// recordProvenance stores the source of the given fields of a song.
+func recordProvenance(ctx context.Context, provenance repository.ProvenanceRepository, songID uint, source, responseHash string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records := make([]models.SongFieldProvenance, 0, len(fields))
+	for _, field := range fields {
+		records = append(records, models.SongFieldProvenance{
+			SongID:       songID,
+			Field:        field,
+			Source:       source,
+			ResponseHash: responseHash,
+			RecordedAt:   now,
+		})
+	}
+
+	if err := provenance.SaveProvenance(ctx, records); err != nil {
+		logger.Error.Printf("[services.recordProvenance]: Error saving provenance for song %d: %v", songID, err)
+		return err
+	}
+	return nil
+}
+
+// filledFields returns the enriched fields of the song that hold a value.
+func filledFields(song *models.Song) []string {
+	var fields []string
+	if song.ReleaseDate != "" {
+		fields = append(fields, models.FieldReleaseDate)
+	}
+	if song.Text != "" {
+		fields = append(fields, models.FieldText)
+	}
+	if song.Link != "" {
+		fields = append(fields, models.FieldLink)
+	}
+	return fields
+}
+
+// changedFields returns the enriched fields whose value differs between the two songs.
+func changedFields(before, after *models.Song) []string {
+	var fields []string
+	if before.ReleaseDate != after.ReleaseDate {
+		fields = append(fields, models.FieldReleaseDate)
+	}
+	if before.Text != after.Text {
+		fields = append(fields, models.FieldText)
+	}
+	if before.Link != after.Link {
+		fields = append(fields, models.FieldLink)
+	}
+	return fields
+}
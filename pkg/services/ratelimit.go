@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"math"
+	"song-library/configs"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"sync"
+	"time"
+)
+
+// bucketSweepInterval is how often buckets that have filled up again are dropped; a full
+// bucket is no different from a missing one.
+const bucketSweepInterval = time.Minute
+
+// RateLimiter enforces the budgets of rate_limit_params per client and route class: a token
+// bucket kept in memory, so every instance limits on its own, and a daily quota counted in
+// the database, which all instances share.
+type RateLimiter struct {
+	quotas repository.QuotaRepository
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*tokenBucket
+	lastSweep time.Time
+}
+
+type bucketKey struct {
+	class  string
+	client string
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimitDecision describes the budget of a request, from whichever of the rate limit and
+// the daily quota is closer to running out. Err is ErrRateLimitExceeded or ErrQuotaExceeded
+// if the request must be turned down, and RetryAfter then says when to try again.
+type RateLimitDecision struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+	Err        error
+}
+
+func NewRateLimiter(quotas repository.QuotaRepository) *RateLimiter {
+	return &RateLimiter{quotas: quotas, buckets: make(map[bucketKey]*tokenBucket)}
+}
+
+// Allow takes a request of client in the route class from its budget. It returns nil if the
+// class has no budget configured.
+func (l *RateLimiter) Allow(ctx context.Context, class, client string) (*RateLimitDecision, error) {
+	params, ok := configs.AppSettings.RateLimitParams.Classes[class]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now()
+
+	var decision *RateLimitDecision
+	if params.RequestsPerMinute > 0 {
+		decision = l.take(bucketKey{class: class, client: client}, params.RequestsPerMinute, params.Burst, now)
+		if decision.Err != nil {
+			return decision, nil
+		}
+	}
+
+	if params.DailyQuota > 0 {
+		used, err := l.quotas.IncrementQuotaUsage(ctx, client, class, now.UTC().Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		untilMidnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+		if used > params.DailyQuota {
+			return &RateLimitDecision{
+				Limit:      params.DailyQuota,
+				Reset:      untilMidnight,
+				RetryAfter: untilMidnight,
+				Err:        utils.ErrQuotaExceeded,
+			}, nil
+		}
+		if remaining := params.DailyQuota - used; decision == nil || remaining < decision.Remaining {
+			decision = &RateLimitDecision{Limit: params.DailyQuota, Remaining: remaining, Reset: untilMidnight}
+		}
+	}
+	return decision, nil
+}
+
+// take removes a token from the bucket of key, which holds up to burst tokens and gains
+// requestsPerMinute of them a minute.
+func (l *RateLimiter) take(key bucketKey, requestsPerMinute, burst int, now time.Time) *RateLimitDecision {
+	if burst <= 0 {
+		burst = requestsPerMinute
+	}
+	rate := float64(requestsPerMinute) / 60
+	capacity := float64(burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		l.sweep(now)
+	}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return &RateLimitDecision{
+			Limit:      burst,
+			Reset:      secondsAt(capacity-bucket.tokens, rate),
+			RetryAfter: secondsAt(1-bucket.tokens, rate),
+			Err:        utils.ErrRateLimitExceeded,
+		}
+	}
+	bucket.tokens--
+	return &RateLimitDecision{
+		Limit:     burst,
+		Remaining: int(bucket.tokens),
+		Reset:     secondsAt(capacity-bucket.tokens, rate),
+	}
+}
+
+// sweep drops the buckets that are full again. The caller must hold the lock.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, bucket := range l.buckets {
+		params := configs.AppSettings.RateLimitParams.Classes[key.class]
+		burst := params.Burst
+		if burst <= 0 {
+			burst = params.RequestsPerMinute
+		}
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*float64(params.RequestsPerMinute)/60 >= float64(burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// secondsAt returns how long it takes to gain the given number of tokens at rate per second.
+func secondsAt(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/pkg/repository/memory"
+	"song-library/utils"
+	"testing"
+	"time"
+)
+
+// setRateLimits configures the budgets of the route classes for the test.
+func setRateLimits(t *testing.T, classes map[string]models.RateLimitClass) {
+	t.Helper()
+	saved := configs.AppSettings.RateLimitParams
+	configs.AppSettings.RateLimitParams = models.RateLimitParams{Enabled: true, Classes: classes}
+	t.Cleanup(func() { configs.AppSettings.RateLimitParams = saved })
+}
+
+func newTestRateLimiter() *RateLimiter {
+	return NewRateLimiter(memory.NewRepositories(memory.NewStore()).Quotas)
+}
+
+func TestTakeDrainsAndRefillsBucket(t *testing.T) {
+	setRateLimits(t, map[string]models.RateLimitClass{models.RateClassRead: {RequestsPerMinute: 60, Burst: 3}})
+	limiter := newTestRateLimiter()
+	key := bucketKey{class: models.RateClassRead, client: "key:1"}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// A minute of 60 requests is one token a second, in a bucket of three.
+	for i, want := range []RateLimitDecision{
+		{Limit: 3, Remaining: 2, Reset: time.Second},
+		{Limit: 3, Remaining: 1, Reset: 2 * time.Second},
+		{Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+		{Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second, Err: utils.ErrRateLimitExceeded},
+	} {
+		if got := limiter.take(key, 60, 3, start); *got != want {
+			t.Errorf("request %d = %+v, want %+v", i+1, *got, want)
+		}
+	}
+
+	halfway := limiter.take(key, 60, 3, start.Add(500*time.Millisecond))
+	if want := (RateLimitDecision{Limit: 3, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond, Err: utils.ErrRateLimitExceeded}); *halfway != want {
+		t.Errorf("request half a token later = %+v, want %+v", *halfway, want)
+	}
+	if refilled := limiter.take(key, 60, 3, start.Add(time.Second)); refilled.Err != nil || refilled.Remaining != 0 {
+		t.Errorf("request a token later = %+v, want it allowed", *refilled)
+	}
+	if full := limiter.take(key, 60, 3, start.Add(time.Hour)); full.Remaining != 2 {
+		t.Errorf("request an hour later = %+v, want a full bucket of 3 less this request", *full)
+	}
+}
+
+func TestTakeDefaultsBurstToRate(t *testing.T) {
+	limiter := newTestRateLimiter()
+	now := time.Now()
+
+	got := limiter.take(bucketKey{class: models.RateClassWrite, client: "key:1"}, 120, 0, now)
+	if want := (RateLimitDecision{Limit: 120, Remaining: 119, Reset: 500 * time.Millisecond}); *got != want {
+		t.Errorf("take without a burst = %+v, want %+v", *got, want)
+	}
+}
+
+func TestTakeKeepsBucketsApart(t *testing.T) {
+	limiter := newTestRateLimiter()
+	now := time.Now()
+
+	if got := limiter.take(bucketKey{class: models.RateClassRead, client: "key:1"}, 1, 1, now); got.Err != nil {
+		t.Fatalf("first request = %+v", *got)
+	}
+	for _, key := range []bucketKey{
+		{class: models.RateClassRead, client: "key:2"},
+		{class: models.RateClassWrite, client: "key:1"},
+	} {
+		if got := limiter.take(key, 1, 1, now); got.Err != nil {
+			t.Errorf("take(%+v) = %+v, want a bucket of its own", key, *got)
+		}
+	}
+	if got := limiter.take(bucketKey{class: models.RateClassRead, client: "key:1"}, 1, 1, now); !errors.Is(got.Err, utils.ErrRateLimitExceeded) {
+		t.Errorf("second request = %+v, want ErrRateLimitExceeded", *got)
+	}
+}
+
+func TestSweepDropsFullBuckets(t *testing.T) {
+	// One request a minute, so a bucket of three takes three minutes to fill.
+	setRateLimits(t, map[string]models.RateLimitClass{models.RateClassRead: {RequestsPerMinute: 1, Burst: 3}})
+	limiter := newTestRateLimiter()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	early := bucketKey{class: models.RateClassRead, client: "key:1"}
+	late := bucketKey{class: models.RateClassRead, client: "key:2"}
+
+	limiter.take(early, 1, 3, start)
+	limiter.take(late, 1, 3, start.Add(30*time.Second))
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("buckets before the sweep = %v, want 2", limiter.buckets)
+	}
+
+	// The early bucket is full again a minute after its request, the late one is not.
+	limiter.take(bucketKey{class: models.RateClassRead, client: "key:3"}, 1, 3, start.Add(61*time.Second))
+	if _, ok := limiter.buckets[early]; ok {
+		t.Error("the full bucket survived the sweep")
+	}
+	if _, ok := limiter.buckets[late]; !ok {
+		t.Error("the sweep dropped a bucket that is not full")
+	}
+	if !limiter.lastSweep.Equal(start.Add(61 * time.Second)) {
+		t.Errorf("lastSweep = %v, want the time of the sweep", limiter.lastSweep)
+	}
+
+	// Dropping a full bucket loses nothing: the client starts again with a full one.
+	if got := limiter.take(early, 1, 3, start.Add(62*time.Second)); got.Remaining != 2 {
+		t.Errorf("request after the sweep = %+v, want a full bucket of 3 less this request", *got)
+	}
+}
+
+func TestAllowCountsDailyQuota(t *testing.T) {
+	setRateLimits(t, map[string]models.RateLimitClass{models.RateClassEnrichment: {DailyQuota: 2}})
+	limiter := newTestRateLimiter()
+	ctx := context.Background()
+
+	for i, remaining := range []int{1, 0} {
+		decision, err := limiter.Allow(ctx, models.RateClassEnrichment, "key:1")
+		if err != nil || decision == nil || decision.Err != nil || decision.Limit != 2 || decision.Remaining != remaining {
+			t.Fatalf("request %d = %+v, %v, want %d of 2 remaining", i+1, decision, err, remaining)
+		}
+		if decision.Reset <= 0 || decision.Reset > 24*time.Hour {
+			t.Errorf("Reset = %v, want the time until midnight UTC", decision.Reset)
+		}
+	}
+
+	decision, err := limiter.Allow(ctx, models.RateClassEnrichment, "key:1")
+	if err != nil || decision == nil || !errors.Is(decision.Err, utils.ErrQuotaExceeded) {
+		t.Fatalf("request over the quota = %+v, %v, want ErrQuotaExceeded", decision, err)
+	}
+	if decision.RetryAfter != decision.Reset || decision.RetryAfter <= 0 || decision.RetryAfter > 24*time.Hour {
+		t.Errorf("request over the quota = %+v, want to retry at midnight UTC", decision)
+	}
+
+	if decision, err := limiter.Allow(ctx, models.RateClassEnrichment, "key:2"); err != nil || decision == nil || decision.Err != nil {
+		t.Errorf("request of another client = %+v, %v, want a quota of its own", decision, err)
+	}
+}
+
+func TestAllowReportsTheCloserBudget(t *testing.T) {
+	setRateLimits(t, map[string]models.RateLimitClass{
+		models.RateClassRead:  {RequestsPerMinute: 60, Burst: 10, DailyQuota: 3},
+		models.RateClassWrite: {RequestsPerMinute: 60, Burst: 1, DailyQuota: 5},
+	})
+	limiter := newTestRateLimiter()
+	ctx := context.Background()
+
+	if decision, err := limiter.Allow(ctx, models.RateClassRead, "key:1"); err != nil || decision == nil || decision.Limit != 3 || decision.Remaining != 2 {
+		t.Errorf("Allow = %+v, %v, want the quota with 2 of 3 remaining", decision, err)
+	}
+
+	if decision, err := limiter.Allow(ctx, models.RateClassWrite, "key:1"); err != nil || decision == nil || decision.Err != nil {
+		t.Fatalf("first write = %+v, %v", decision, err)
+	}
+	decision, err := limiter.Allow(ctx, models.RateClassWrite, "key:1")
+	if err != nil || decision == nil || !errors.Is(decision.Err, utils.ErrRateLimitExceeded) || decision.Limit != 1 {
+		t.Fatalf("second write = %+v, %v, want ErrRateLimitExceeded", decision, err)
+	}
+	// A request turned down by the rate limit is not taken from the quota.
+	used, err := limiter.quotas.IncrementQuotaUsage(ctx, "key:1", models.RateClassWrite, time.Now().UTC().Format("2006-01-02"))
+	if err != nil || used != 2 {
+		t.Errorf("quota used after a refused request = %d, %v, want 1 before this increment", used, err)
+	}
+
+	if decision, err := limiter.Allow(ctx, "unconfigured", "key:1"); decision != nil || err != nil {
+		t.Errorf("Allow in a class without a budget = %+v, %v, want nil", decision, err)
+	}
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,13 +17,35 @@ import (
 	"time"
 )
 
-func GetSongs(group, song string, page, limit int) (songs []models.Song, err error) {
+type SongService struct {
+	songs      repository.SongRepository
+	links      repository.LinkRepository
+	provenance repository.ProvenanceRepository
+	merges     repository.MergeRepository
+	uow        repository.UnitOfWork
+}
+
+// NewSongService reads through repos and runs every change that touches more than one
+// row through uow.
+func NewSongService(repos repository.Repositories, uow repository.UnitOfWork) *SongService {
+	return &SongService{
+		songs:      repos.Songs,
+		links:      repos.Links,
+		provenance: repos.Provenance,
+		merges:     repos.Merges,
+		uow:        uow,
+	}
+}
+
+// GetSongs lists songs; soft-deleted ones are only included for admin callers that ask
+// for them with includeDeleted.
+func (s *SongService) GetSongs(ctx context.Context, group, song string, includeDeleted bool, page, limit int) (songs []models.Song, err error) {
 	if page <= 0 || limit <= 0 || limit > 100 {
 		logger.Error.Printf("services.GetSongs: page %d or limit %d", page, limit)
 		return nil, utils.ErrInvalidPaginationParams
 	}
 
-	songs, err = repository.GetSongs(group, song, page, limit)
+	songs, err = s.songs.GetSongs(ctx, group, song, includeDeleted, page, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -28,8 +53,25 @@ func GetSongs(group, song string, page, limit int) (songs []models.Song, err err
 	return songs, nil
 }
 
-func GetSongByID(id uint) (song *models.Song, err error) {
-	song, err = repository.GetSongByID(id)
+func (s *SongService) GetSongByID(ctx context.Context, id uint) (song *models.Song, err error) {
+	return findSong(ctx, s.songs, id)
+}
+
+// GetSongIncludingDeleted returns the song with the given ID even if it is soft deleted.
+func (s *SongService) GetSongIncludingDeleted(ctx context.Context, id uint) (*models.Song, error) {
+	song, err := s.songs.GetSongByID(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if song == nil {
+		return nil, utils.ErrSongNotFound
+	}
+	return song, nil
+}
+
+// findSong returns the active song with the given ID, or ErrSongNotFound.
+func findSong(ctx context.Context, songs repository.SongRepository, id uint) (*models.Song, error) {
+	song, err := songs.GetSongByID(ctx, id, false)
 	if err != nil {
 		return nil, err
 	}
@@ -41,40 +83,66 @@ func GetSongByID(id uint) (song *models.Song, err error) {
 	return song, nil
 }
 
-func UpdateSong(id uint, songUpdate *models.Song) error {
-	existingSong, err := repository.GetSongByID(id)
-	if err != nil {
-		logger.Error.Printf("[services.UpdateSong]: Error getting existing song: %v", err)
-		return err
+func (s *SongService) UpdateSong(ctx context.Context, id uint, songUpdate models.UpdateSongRequest) error {
+	var link models.SongLink
+	if songUpdate.Link != "" {
+		var err error
+		link, err = NormalizeLink(songUpdate.Link)
+		if err != nil {
+			logger.Error.Printf("[services.UpdateSong]: Invalid link %q: %v", songUpdate.Link, err)
+			return err
+		}
 	}
 
-	if existingSong == nil {
-		logger.Error.Printf("[services.UpdateSong]: Song does not exist")
-		return utils.ErrSongNotFound
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		return changeSong(ctx, repos, id, link, func(song *models.Song) {
+			song.Group = songUpdate.Group
+			song.Song = songUpdate.Song
+			song.ReleaseDate = songUpdate.ReleaseDate
+			song.Text = songUpdate.Text
+			song.Link = link.URL
+			song.Language = songUpdate.Language
+		})
+	})
+}
+
+// changeSong applies change to the active song id and saves it, recording the enriched
+// fields it changed as set by hand. link, unless empty, is the normalised new link of the
+// song, which is added to its links.
+func changeSong(ctx context.Context, repos repository.Repositories, id uint, link models.SongLink, change func(song *models.Song)) error {
+	existingSong, err := findSong(ctx, repos.Songs, id)
+	if err != nil {
+		logger.Error.Printf("[services.changeSong]: Error getting existing song: %v", err)
+		return err
 	}
 
-	existingSong.Group = songUpdate.Group
-	existingSong.Song = songUpdate.Song
-	existingSong.ReleaseDate = songUpdate.ReleaseDate
-	existingSong.Text = songUpdate.Text
-	existingSong.Link = songUpdate.Link
+	before := *existingSong
+	change(existingSong)
 	existingSong.UpdatedAt = time.Now()
-	if err := repository.UpdateSong(existingSong); err != nil {
+	existingSong.UpdatedBy = models.ActorFromContext(ctx)
+	if err := repos.Songs.UpdateSong(ctx, existingSong); err != nil {
 		return err
 	}
-	return nil
+	if link.URL != "" {
+		if err := attachLink(ctx, repos.Links, id, link); err != nil {
+			return err
+		}
+	}
+	return recordProvenance(ctx, repos.Provenance, id, models.SourceManual, "", changedFields(&before, existingSong)...)
 }
 
-func AddSong(newSongRequest models.NewSongRequest) (*models.Song, error) {
+func (s *SongService) AddSong(ctx context.Context, newSongRequest models.NewSongRequest) (*models.Song, error) {
 	song := &models.Song{
 		Group:       newSongRequest.Group,
 		Song:        newSongRequest.Song,
 		ReleaseDate: "",
 		Text:        "",
 		Link:        "",
+		Language:    newSongRequest.Language,
+		UpdatedBy:   models.ActorFromContext(ctx),
 	}
 
-	exists, err := repository.SongExists(song.Group, song.Song)
+	exists, err := s.songs.SongExists(ctx, song.Group, song.Song)
 	if err != nil {
 		return nil, err
 	}
@@ -82,72 +150,132 @@ func AddSong(newSongRequest models.NewSongRequest) (*models.Song, error) {
 		return nil, utils.ErrSongAlreadyExists
 	}
 
-	apiURL := fmt.Sprintf(configs.AppSettings.AppParams.ApiURL, url.QueryEscape(song.Group), url.QueryEscape(song.Song))
-	logger.Info.Printf("Fetching song info from API: %s", apiURL)
-
-	resp, err := http.Get(apiURL)
+	responseHash := ""
+	songDetail, hash, err := fetchSongDetail(ctx, song.Group, song.Song)
 	if err != nil {
-		logger.Error.Printf("[services.AddSong] Failed to fetch song info: %s", err)
+		logger.Error.Printf("[services.AddSong] Failed to enrich song: %s", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 	} else {
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				logger.Error.Printf("[services.AddSong] Failed to close response body: %v", err)
-			}
-		}(resp.Body)
-
-		if resp.StatusCode == http.StatusOK {
-			var songDetail models.SongDetail
-			if err := json.NewDecoder(resp.Body).Decode(&songDetail); err != nil {
-				logger.Error.Printf("[services.AddSong] Failed to decode response: %s", err)
+		song.ReleaseDate = songDetail.ReleaseDate
+		song.Text = songDetail.Text
+		responseHash = hash
+		if songDetail.Link != "" {
+			if link, err := NormalizeLink(songDetail.Link); err != nil {
+				logger.Error.Printf("[services.AddSong] Provider returned invalid link %q: %v", songDetail.Link, err)
 			} else {
-				song.ReleaseDate = songDetail.ReleaseDate
-				song.Text = songDetail.Text
-				song.Link = songDetail.Link
+				song.Link = link.URL
+				song.Links = []models.SongLink{link}
 			}
-		} else {
-			logger.Error.Printf("[services.AddSong] API returned non-200 status: %d", resp.StatusCode)
 		}
 	}
 
-	if err := repository.AddSong(song); err != nil {
+	err = s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Songs.AddSong(ctx, song); err != nil {
+			return err
+		}
+		for _, link := range song.Links {
+			if err := attachLink(ctx, repos.Links, song.ID, link); err != nil {
+				return err
+			}
+		}
+		if responseHash == "" {
+			return nil
+		}
+		return recordProvenance(ctx, repos.Provenance, song.ID, models.SourceProvider, responseHash, filledFields(song)...)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return song, nil
 }
 
-func SoftDeleteSong(id uint) error {
-	song, err := repository.GetSongByID(id)
+// fetchSongDetail requests the song details from the metadata provider and returns them
+// together with the SHA-256 hash of the raw response body.
+func fetchSongDetail(ctx context.Context, group, song string) (*models.SongDetail, string, error) {
+	apiURL := fmt.Sprintf(configs.AppSettings.AppParams.ApiURL, url.QueryEscape(group), url.QueryEscape(song))
+	logger.Info.Printf("Fetching song info from API: %s", apiURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return err
+		logger.Error.Printf("[services.fetchSongDetail] Failed to create request: %s", err)
+		return nil, "", utils.ErrFailedToFetchSongInfoFromAPI
 	}
 
-	if song == nil {
-		logger.Error.Printf("[services.SoftDeleteSong]: Song does not exist")
-		return utils.ErrSongNotFound
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Error.Printf("[services.fetchSongDetail] Failed to fetch song info: %s", err)
+		return nil, "", utils.ErrFailedToFetchSongInfoFromAPI
 	}
-	return repository.SoftDeleteSong(id)
-}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Error.Printf("[services.fetchSongDetail] Failed to close response body: %v", err)
+		}
+	}(resp.Body)
 
-func HardDeleteSong(id uint) (err error) {
-	song, err := repository.GetSongByID(id)
+	if resp.StatusCode != http.StatusOK {
+		logger.Error.Printf("[services.fetchSongDetail] API returned non-200 status: %d", resp.StatusCode)
+		return nil, "", utils.ErrAPIRequestFailed
+	}
+
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		logger.Error.Printf("[services.fetchSongDetail] Failed to read response: %s", err)
+		return nil, "", utils.ErrInvalidResponse
 	}
-	if song == nil {
-		logger.Error.Printf("[services.HardDeleteSong]: Song does not exist")
-		return utils.ErrSongNotFound
+
+	var songDetail models.SongDetail
+	if err := json.Unmarshal(body, &songDetail); err != nil {
+		logger.Error.Printf("[services.fetchSongDetail] Failed to decode response: %s", err)
+		return nil, "", utils.ErrInvalidResponse
 	}
-	return repository.HardDeleteSong(id)
+
+	sum := sha256.Sum256(body)
+	return &songDetail, hex.EncodeToString(sum[:]), nil
+}
+
+func (s *SongService) SoftDeleteSong(ctx context.Context, id uint) error {
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		return softDeleteSong(ctx, repos, id)
+	})
+}
+
+func softDeleteSong(ctx context.Context, repos repository.Repositories, id uint) error {
+	if _, err := findSong(ctx, repos.Songs, id); err != nil {
+		logger.Error.Printf("[services.SoftDeleteSong]: Error getting song: %v", err)
+		return err
+	}
+	return repos.Songs.SoftDeleteSong(ctx, id, models.ActorFromContext(ctx))
+}
+
+func (s *SongService) HardDeleteSong(ctx context.Context, id uint) (err error) {
+	return s.uow.Do(ctx, func(repos repository.Repositories) error {
+		if _, err := findSong(ctx, repos.Songs, id); err != nil {
+			logger.Error.Printf("[services.HardDeleteSong]: Error getting song: %v", err)
+			return err
+		}
+		if err := repos.Links.DeleteLinksBySongID(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.Songs.HardDeleteSong(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.Merges.DeleteRedirectsBySongID(ctx, id); err != nil {
+			return err
+		}
+		return repos.Provenance.DeleteProvenanceBySongID(ctx, id)
+	})
 }
 
-func GetLyrics(song string, page int, limit int) ([]string, error) {
+func (s *SongService) GetLyrics(ctx context.Context, song string, page int, limit int) ([]string, error) {
 	if page <= 0 || limit <= 0 || limit > 100 {
 		logger.Error.Printf("services.GetLyrics: page %d or limit %d", page, limit)
 		return nil, utils.ErrInvalidPaginationParams
 	}
-	verses, err := repository.GetLyrics(song, page, limit)
+	verses, err := s.songs.GetLyrics(ctx, song, page, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -155,13 +283,13 @@ func GetLyrics(song string, page int, limit int) ([]string, error) {
 	return verses, nil
 }
 
-func GetLyricsByText(searchText string, page int, limit int) ([]string, error) {
+func (s *SongService) GetLyricsByText(ctx context.Context, searchText string, page int, limit int) ([]string, error) {
 	if page <= 0 || limit <= 0 || limit > 100 {
 		logger.Error.Printf("services.GetLyrics: page %d or limit %d", page, limit)
 		return nil, utils.ErrInvalidPaginationParams
 	}
 
-	verses, err := repository.GetLyricsByText(searchText, page, limit)
+	verses, err := s.songs.GetLyricsByText(ctx, searchText, page, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"song-library/models"
+	"song-library/utils"
+	"strconv"
+	"time"
+)
+
+const tokenIssuer = "song-library"
+
+// TokenIssuer signs and verifies the access tokens of users.
+type TokenIssuer struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	ttl       time.Duration
+}
+
+type accessClaims struct {
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	LibraryID uint   `json:"library_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewTokenIssuer sets up signing as configured in auth_params: HS256 with the secret in the
+// JWT_SECRET variable, or RS256 with the RSA key in jwt_private_key_file.
+func NewTokenIssuer(params models.AuthParams) (*TokenIssuer, error) {
+	issuer := &TokenIssuer{ttl: time.Duration(params.AccessTokenTTLMinutes) * time.Minute}
+	if issuer.ttl <= 0 {
+		issuer.ttl = 15 * time.Minute
+	}
+
+	switch params.JWTAlgorithm {
+	case "HS256", "":
+		secret := os.Getenv("JWT_SECRET")
+		if len(secret) < 32 {
+			return nil, errors.New("JWT_SECRET environment variable must hold at least 32 characters")
+		}
+		issuer.method = jwt.SigningMethodHS256
+		issuer.signKey, issuer.verifyKey = []byte(secret), []byte(secret)
+	case "RS256":
+		data, err := os.ReadFile(params.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading jwt_private_key_file: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing jwt_private_key_file: %w", err)
+		}
+		issuer.method = jwt.SigningMethodRS256
+		issuer.signKey, issuer.verifyKey = key, &key.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported jwt_algorithm %q", params.JWTAlgorithm)
+	}
+	return issuer, nil
+}
+
+func (t *TokenIssuer) IssueAccessToken(user *models.User) (string, error) {
+	now := time.Now()
+	claims := accessClaims{
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.ttl)),
+		},
+	}
+	if user.LibraryID != nil {
+		claims.LibraryID = *user.LibraryID
+	}
+	return jwt.NewWithClaims(t.method, claims).SignedString(t.signKey)
+}
+
+// ParseAccessToken verifies the token and returns the user it was issued to, with the
+// scopes of the role and the library the user had at the time.
+func (t *TokenIssuer) ParseAccessToken(token string) (*models.Principal, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return t.verifyKey, nil
+	}, jwt.WithValidMethods([]string{t.method.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+	scopes, ok := models.RoleScopes[claims.Role]
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	return &models.Principal{Name: claims.Username, UserID: uint(userID), LibraryID: claims.LibraryID, Scopes: scopes}, nil
+}
+
+func (t *TokenIssuer) TTL() time.Duration {
+	return t.ttl
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"reflect"
+	"song-library/models"
+	services "song-library/pkg/services"
+	"song-library/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef0123"
+
+// newHS256Issuer returns a token issuer signing with testJWTSecret.
+func newHS256Issuer(t *testing.T) *services.TokenIssuer {
+	t.Helper()
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	issuer, err := services.NewTokenIssuer(models.AuthParams{AccessTokenTTLMinutes: 5})
+	if err != nil {
+		t.Fatalf("NewTokenIssuer: %v", err)
+	}
+	return issuer
+}
+
+// accessClaims are the claims of a valid access token of user 42, an editor of library 3.
+func accessClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"username":   "alice",
+		"role":       models.RoleEditor,
+		"library_id": 3,
+		"iss":        "song-library",
+		"sub":        "42",
+		"iat":        now.Unix(),
+		"exp":        now.Add(time.Minute).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("signing %s token: %v", method.Alg(), err)
+	}
+	return token
+}
+
+func TestParseAccessToken(t *testing.T) {
+	issuer := newHS256Issuer(t)
+
+	principal, err := issuer.ParseAccessToken(sign(t, jwt.SigningMethodHS256, []byte(testJWTSecret), accessClaims()))
+	if err != nil {
+		t.Fatalf("ParseAccessToken: %v", err)
+	}
+	want := &models.Principal{Name: "alice", UserID: 42, LibraryID: 3, Scopes: models.RoleScopes[models.RoleEditor]}
+	if !reflect.DeepEqual(principal, want) {
+		t.Errorf("ParseAccessToken = %+v, want %+v", principal, want)
+	}
+
+	libraryID := uint(3)
+	issued, err := issuer.IssueAccessToken(&models.User{ID: 42, Username: "alice", Role: models.RoleEditor, LibraryID: &libraryID})
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	if principal, err := issuer.ParseAccessToken(issued); err != nil || !reflect.DeepEqual(principal, want) {
+		t.Errorf("ParseAccessToken of an issued token = %+v, %v, want %+v", principal, err, want)
+	}
+}
+
+func TestParseAccessTokenRejects(t *testing.T) {
+	issuer := newHS256Issuer(t)
+	secret := []byte(testJWTSecret)
+	with := func(change func(claims jwt.MapClaims)) jwt.MapClaims {
+		claims := accessClaims()
+		change(claims)
+		return claims
+	}
+	// The payload of an admin token under the signature of the editor token.
+	valid := strings.Split(sign(t, jwt.SigningMethodHS256, secret, accessClaims()), ".")
+	admin := strings.Split(sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["role"] = models.RoleAdmin })), ".")
+	tampered := valid[0] + "." + admin[1] + "." + valid[2]
+
+	for name, token := range map[string]string{
+		"alg none":       sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, accessClaims()),
+		"alg HS384":      sign(t, jwt.SigningMethodHS384, secret, accessClaims()),
+		"alg HS512":      sign(t, jwt.SigningMethodHS512, secret, accessClaims()),
+		"other secret":   sign(t, jwt.SigningMethodHS256, []byte(strings.Repeat("x", 36)), accessClaims()),
+		"other issuer":   sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["iss"] = "someone-else" })),
+		"no issuer":      sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { delete(c, "iss") })),
+		"expired":        sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })),
+		"no expiry":      sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { delete(c, "exp") })),
+		"not yet valid":  sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Hour).Unix() })),
+		"unknown role":   sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["role"] = "superuser" })),
+		"no role":        sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { delete(c, "role") })),
+		"subject a name": sign(t, jwt.SigningMethodHS256, secret, with(func(c jwt.MapClaims) { c["sub"] = "alice" })),
+		"changed claims": tampered,
+		"garbage":        "not.a.token",
+		"empty":          "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if principal, err := issuer.ParseAccessToken(token); !errors.Is(err, utils.ErrUnauthorized) {
+				t.Errorf("ParseAccessToken = %+v, %v, want ErrUnauthorized", principal, err)
+			}
+		})
+	}
+}
+
+func TestParseAccessTokenRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	issuer, err := services.NewTokenIssuer(models.AuthParams{JWTAlgorithm: "RS256", JWTPrivateKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewTokenIssuer: %v", err)
+	}
+
+	if _, err := issuer.ParseAccessToken(sign(t, jwt.SigningMethodRS256, key, accessClaims())); err != nil {
+		t.Errorf("ParseAccessToken of an RS256 token: %v", err)
+	}
+
+	// A verifier that took the alg of the token would check this HMAC against the public key.
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPublicKey(t, &key.PublicKey)})
+	if _, err := issuer.ParseAccessToken(sign(t, jwt.SigningMethodHS256, publicPEM, accessClaims())); !errors.Is(err, utils.ErrUnauthorized) {
+		t.Errorf("ParseAccessToken of an HS256 token keyed with the public key = %v, want ErrUnauthorized", err)
+	}
+}
+
+func mustMarshalPublicKey(t *testing.T, key *rsa.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return der
+}
+
+func TestNewTokenIssuerRejects(t *testing.T) {
+	t.Setenv("JWT_SECRET", strings.Repeat("x", 31))
+	if _, err := services.NewTokenIssuer(models.AuthParams{}); err == nil {
+		t.Error("NewTokenIssuer with a 31 character secret succeeded, want an error")
+	}
+
+	t.Setenv("JWT_SECRET", testJWTSecret)
+	for _, params := range []models.AuthParams{
+		{JWTAlgorithm: "none"},
+		{JWTAlgorithm: "HS512"},
+		{JWTAlgorithm: "RS256", JWTPrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		if _, err := services.NewTokenIssuer(params); err == nil {
+			t.Errorf("NewTokenIssuer(%+v) succeeded, want an error", params)
+		}
+	}
+}
+
+func TestIssueAccessTokenLifetime(t *testing.T) {
+	issuer := newHS256Issuer(t)
+	token, err := issuer.IssueAccessToken(&models.User{ID: 7, Username: "bob", Role: models.RoleViewer})
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return []byte(testJWTSecret), nil }); err != nil {
+		t.Fatalf("ParseWithClaims: %v", err)
+	}
+	exp, _ := claims.GetExpirationTime()
+	iat, _ := claims.GetIssuedAt()
+	if exp == nil || iat == nil || exp.Sub(iat.Time) != 5*time.Minute || issuer.TTL() != 5*time.Minute {
+		t.Errorf("token lives from %v to %v, TTL %v, want 5 minutes", iat, exp, issuer.TTL())
+	}
+	if claims["sub"] != strconv.Itoa(7) || claims["iss"] != "song-library" || claims["library_id"] != nil {
+		t.Errorf("claims = %v, want subject 7, issuer song-library and no library", claims)
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/crypto/bcrypt"
+	"song-library/configs"
+	"song-library/logger"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// dummyPasswordHash is compared against when a login names an unknown user, so the
+	// answer takes as long as for a wrong password.
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+type UserService struct {
+	users     repository.UserRepository
+	libraries repository.LibraryRepository
+	tokens    *TokenIssuer
+}
+
+func NewUserService(users repository.UserRepository, libraries repository.LibraryRepository, tokens *TokenIssuer) *UserService {
+	return &UserService{users: users, libraries: libraries, tokens: tokens}
+}
+
+func (s *UserService) GetUsers(ctx context.Context) ([]models.User, error) {
+	return s.users.GetUsers(ctx)
+}
+
+// Register creates an account with the viewer role in the default library, if
+// self-registration is allowed.
+func (s *UserService) Register(ctx context.Context, credentials models.Credentials) (*models.User, error) {
+	if !configs.AppSettings.AuthParams.AllowRegistration {
+		return nil, utils.ErrForbidden
+	}
+	libraryID := uint(models.DefaultLibraryID)
+	return s.CreateUser(ctx, models.NewUserRequest{
+		Username:  credentials.Username,
+		Password:  credentials.Password,
+		Role:      models.RoleViewer,
+		LibraryID: &libraryID,
+	})
+}
+
+func (s *UserService) CreateUser(ctx context.Context, request models.NewUserRequest) (*models.User, error) {
+	username := strings.TrimSpace(request.Username)
+	if len(username) < 3 || len(username) > 64 || strings.ContainsAny(username, " \t\r\n") {
+		return nil, utils.ErrInvalidUserRequest
+	}
+	// bcrypt only looks at the first 72 bytes.
+	if len(request.Password) < 8 || len(request.Password) > 72 {
+		return nil, utils.ErrInvalidUserRequest
+	}
+	scopes, ok := models.RoleScopes[request.Role]
+	if !ok {
+		return nil, utils.ErrInvalidUserRequest
+	}
+	if err := validateLibraryBinding(ctx, s.libraries, request.LibraryID, scopes, utils.ErrInvalidUserRequest); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Error.Printf("[services.CreateUser]: Error hashing password: %v", err)
+		return nil, utils.ErrUnexpectedError
+	}
+
+	user := &models.User{Username: username, PasswordHash: string(hash), Role: request.Role, LibraryID: request.LibraryID}
+	if err := s.users.AddUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUserRole changes the role of a user. Access tokens already issued keep the old
+// role until they expire; refreshed ones carry the new role.
+func (s *UserService) UpdateUserRole(ctx context.Context, id uint, role string) (*models.User, error) {
+	scopes, ok := models.RoleScopes[role]
+	if !ok {
+		return nil, utils.ErrInvalidUserRequest
+	}
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLibraryBinding(ctx, s.libraries, user.LibraryID, scopes, utils.ErrInvalidUserRequest); err != nil {
+		return nil, err
+	}
+	if err := s.users.UpdateUserRole(ctx, id, role); err != nil {
+		return nil, err
+	}
+	return s.findUser(ctx, id)
+}
+
+func (s *UserService) Login(ctx context.Context, credentials models.Credentials) (*models.TokenResponse, error) {
+	user, err := s.users.GetUserByUsername(ctx, strings.TrimSpace(credentials.Username))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(credentials.Password))
+		return nil, utils.ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)) != nil {
+		return nil, utils.ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// Refresh trades a refresh token for new tokens; the old refresh token is used up. A
+// refresh token presented again after that may have been stolen, so every refresh token
+// of the user is revoked.
+func (s *UserService) Refresh(ctx context.Context, refreshToken string) (*models.TokenResponse, error) {
+	token, err := s.users.GetRefreshTokenByHash(ctx, hashSecret(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		logger.Warning.Printf("[services.Refresh]: Revoked refresh token %d of user %d reused", token.ID, token.UserID)
+		if err := s.users.RevokeRefreshTokensByUserID(ctx, token.UserID, now); err != nil {
+			return nil, err
+		}
+		return nil, utils.ErrUnauthorized
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, utils.ErrUnauthorized
+	}
+
+	revoked, err := s.users.RevokeRefreshToken(ctx, token.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, utils.ErrUnauthorized
+	}
+
+	user, err := s.users.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, utils.ErrUnauthorized
+	}
+	return s.issueTokens(ctx, user)
+}
+
+func (s *UserService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.users.GetRefreshTokenByHash(ctx, hashSecret(refreshToken))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return utils.ErrUnauthorized
+	}
+	_, err = s.users.RevokeRefreshToken(ctx, token.ID, time.Now())
+	return err
+}
+
+// Authenticate verifies an access token and returns the user it belongs to.
+func (s *UserService) Authenticate(accessToken string) (*models.Principal, error) {
+	return s.tokens.ParseAccessToken(accessToken)
+}
+
+func (s *UserService) issueTokens(ctx context.Context, user *models.User) (*models.TokenResponse, error) {
+	accessToken, err := s.tokens.IssueAccessToken(user)
+	if err != nil {
+		logger.Error.Printf("[services.issueTokens]: Error signing access token: %v", err)
+		return nil, utils.ErrUnexpectedError
+	}
+
+	plain, err := generateSecret("slr_")
+	if err != nil {
+		logger.Error.Printf("[services.issueTokens]: Error generating refresh token: %v", err)
+		return nil, utils.ErrUnexpectedError
+	}
+	ttl := time.Duration(configs.AppSettings.AuthParams.RefreshTokenTTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 30 * 24 * time.Hour
+	}
+	refreshToken := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashSecret(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.users.AddRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokens.TTL().Seconds()),
+		RefreshToken: plain,
+	}, nil
+}
+
+func (s *UserService) findUser(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.users.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, utils.ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"song-library/configs"
+	"song-library/models"
+	"song-library/pkg/repository"
+	"song-library/pkg/repository/memory"
+	services "song-library/pkg/services"
+	"song-library/utils"
+	"testing"
+	"time"
+)
+
+const testPassword = "correct horse battery"
+
+// newUserService returns a user service on the memory backend holding one editor, alice.
+func newUserService(t *testing.T) (*services.UserService, repository.Repositories) {
+	t.Helper()
+	discardLogs()
+	configs.AppSettings.AuthParams = models.AuthParams{RefreshTokenTTLHours: 1}
+	repos := memory.NewRepositories(memory.NewStore())
+	users := services.NewUserService(repos.Users, repos.Libraries, newHS256Issuer(t))
+	if _, err := users.CreateUser(context.Background(), models.NewUserRequest{Username: "alice", Password: testPassword, Role: models.RoleEditor}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return users, repos
+}
+
+func login(t *testing.T, users *services.UserService) *models.TokenResponse {
+	t.Helper()
+	tokens, err := users.Login(context.Background(), models.Credentials{Username: "alice", Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return tokens
+}
+
+func TestLogin(t *testing.T) {
+	users, _ := newUserService(t)
+	ctx := context.Background()
+
+	tokens := login(t, users)
+	principal, err := users.Authenticate(tokens.AccessToken)
+	if err != nil || principal.Name != "alice" || !principal.HasScope(models.ScopeSongsWrite) {
+		t.Errorf("Authenticate = %+v, %v, want alice with the editor scopes", principal, err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn != 300 || tokens.RefreshToken == "" {
+		t.Errorf("Login = %+v, want a bearer token for 300 seconds and a refresh token", tokens)
+	}
+
+	for _, credentials := range []models.Credentials{
+		{Username: "alice", Password: "wrong password"},
+		{Username: "alice", Password: ""},
+		{Username: "mallory", Password: testPassword},
+	} {
+		if _, err := users.Login(ctx, credentials); !errors.Is(err, utils.ErrInvalidCredentials) {
+			t.Errorf("Login(%q, %q) = %v, want ErrInvalidCredentials", credentials.Username, credentials.Password, err)
+		}
+	}
+}
+
+// TestLoginTakesAsLongForUnknownUsers makes sure a login naming an unknown user still
+// compares a bcrypt hash, so the answer does not tell which usernames exist.
+func TestLoginTakesAsLongForUnknownUsers(t *testing.T) {
+	users, _ := newUserService(t)
+	ctx := context.Background()
+
+	fastest := func(username string) time.Duration {
+		var best time.Duration
+		for i := 0; i < 3; i++ {
+			start := time.Now()
+			if _, err := users.Login(ctx, models.Credentials{Username: username, Password: "wrong password"}); !errors.Is(err, utils.ErrInvalidCredentials) {
+				t.Fatalf("Login(%q) = %v, want ErrInvalidCredentials", username, err)
+			}
+			if elapsed := time.Since(start); i == 0 || elapsed < best {
+				best = elapsed
+			}
+		}
+		return best
+	}
+	wrongPassword := fastest("alice")
+	unknownUser := fastest("mallory")
+	// bcrypt takes tens of milliseconds, a lookup alone a few microseconds.
+	if unknownUser < wrongPassword/3 {
+		t.Errorf("Login of an unknown user took %v, of a wrong password %v, want them alike", unknownUser, wrongPassword)
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	users, repos := newUserService(t)
+	ctx := context.Background()
+	first := login(t, users)
+
+	second, err := users.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Refresh returned the same refresh token")
+	}
+	if _, err := users.Authenticate(second.AccessToken); err != nil {
+		t.Errorf("Authenticate with the refreshed access token: %v", err)
+	}
+
+	alice, err := repos.Users.GetUserByUsername(ctx, "alice")
+	if err != nil || alice == nil {
+		t.Fatalf("GetUserByUsername = %+v, %v", alice, err)
+	}
+	if _, err := users.UpdateUserRole(ctx, alice.ID, models.RoleViewer); err != nil {
+		t.Fatalf("UpdateUserRole: %v", err)
+	}
+	third, err := users.Refresh(ctx, second.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if principal, err := users.Authenticate(third.AccessToken); err != nil || principal.HasScope(models.ScopeSongsWrite) {
+		t.Errorf("Authenticate after the role change = %+v, %v, want the viewer scopes", principal, err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesEveryToken(t *testing.T) {
+	users, _ := newUserService(t)
+	ctx := context.Background()
+	stolen := login(t, users)
+	otherDevice := login(t, users)
+
+	rotated, err := users.Refresh(ctx, stolen.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, err := users.Refresh(ctx, stolen.RefreshToken); !errors.Is(err, utils.ErrUnauthorized) {
+		t.Fatalf("Refresh with a used token = %v, want ErrUnauthorized", err)
+	}
+
+	for name, token := range map[string]string{"rotated": rotated.RefreshToken, "other device": otherDevice.RefreshToken} {
+		if _, err := users.Refresh(ctx, token); !errors.Is(err, utils.ErrUnauthorized) {
+			t.Errorf("Refresh with the %s token after reuse = %v, want ErrUnauthorized", name, err)
+		}
+	}
+	if _, err := users.Login(ctx, models.Credentials{Username: "alice", Password: testPassword}); err != nil {
+		t.Errorf("Login after reuse = %v, want a new session", err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	users, repos := newUserService(t)
+	ctx := context.Background()
+	loggedOut := login(t, users)
+	if err := users.Logout(ctx, loggedOut.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	alice, err := repos.Users.GetUserByUsername(ctx, "alice")
+	if err != nil || alice == nil {
+		t.Fatalf("GetUserByUsername = %+v, %v", alice, err)
+	}
+	const expired = "slr_expired"
+	if err := repos.Users.AddRefreshToken(ctx, &models.RefreshToken{UserID: alice.ID, TokenHash: sha256Hex(expired), ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatalf("AddRefreshToken: %v", err)
+	}
+
+	for name, token := range map[string]string{"logged out": loggedOut.RefreshToken, "expired": expired, "unknown": "slr_unknown", "access token": loggedOut.AccessToken} {
+		if _, err := users.Refresh(ctx, token); !errors.Is(err, utils.ErrUnauthorized) {
+			t.Errorf("Refresh with the %s token = %v, want ErrUnauthorized", name, err)
+		}
+	}
+}
+
+// sha256Hex hashes a secret the way refresh tokens are stored.
+func sha256Hex(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
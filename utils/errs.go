@@ -27,4 +27,49 @@ var (
 	ErrGroupNotFound                = errors.New("ErrGroupNotFound")
 	ErrAPIRequestFailed             = errors.New("ErrAPIRequestFailed")
 	ErrInvalidResponse              = errors.New("ErrInvalidResponse")
+	ErrLinkNotFound                 = errors.New("ErrLinkNotFound")
+	ErrLinkAlreadyExists            = errors.New("ErrLinkAlreadyExists")
+	ErrInvalidMergeRequest          = errors.New("ErrInvalidMergeRequest")
+	ErrRequestTimeout               = errors.New("ErrRequestTimeout")
+	ErrUnauthorized                 = errors.New("ErrUnauthorized")
+	ErrForbidden                    = errors.New("ErrForbidden")
+	ErrAPIKeyNotFound               = errors.New("ErrAPIKeyNotFound")
+	ErrInvalidAPIKeyRequest         = errors.New("ErrInvalidAPIKeyRequest")
+	ErrInvalidCredentials           = errors.New("ErrInvalidCredentials")
+	ErrInvalidUserRequest           = errors.New("ErrInvalidUserRequest")
+	ErrUserAlreadyExists            = errors.New("ErrUserAlreadyExists")
+	ErrUserNotFound                 = errors.New("ErrUserNotFound")
+	ErrInvalidLibraryRequest        = errors.New("ErrInvalidLibraryRequest")
+	ErrLibraryAlreadyExists         = errors.New("ErrLibraryAlreadyExists")
+	ErrLibraryNotFound              = errors.New("ErrLibraryNotFound")
+	ErrRateLimitExceeded            = errors.New("ErrRateLimitExceeded")
+	ErrQuotaExceeded                = errors.New("ErrQuotaExceeded")
+	ErrInvalidLanguage              = errors.New("ErrInvalidLanguage")
+	ErrIdempotencyKeyInUse          = errors.New("ErrIdempotencyKeyInUse")
+	ErrIdempotencyKeyReused         = errors.New("ErrIdempotencyKeyReused")
+	ErrBatchTooLarge                = errors.New("ErrBatchTooLarge")
+	ErrBatchRolledBack              = errors.New("ErrBatchRolledBack")
 )
+
+// FieldError is one invalid value of a request, located by a JSON pointer (RFC 6901) into
+// the request body. Code is the name of the sentinel error describing the problem.
+type FieldError struct {
+	Pointer string `json:"pointer"`
+	Code    string `json:"code"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// ValidationError reports every invalid value of a request at once. errors.Is matches it
+// against Err, which decides how the request as a whole is answered.
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}